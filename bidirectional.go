@@ -0,0 +1,254 @@
+package pathfind
+
+import (
+	"context"
+	"fmt"
+	"github.com/df-mc/dragonfly/server/block/cube"
+	"github.com/df-mc/dragonfly/server/world"
+	"math"
+	"slices"
+	"time"
+)
+
+// PredecessorEvaluator may be implemented by a NodeEvaluator with moves that cannot be reversed, such as falls,
+// so that FindPathBidirectional can search backwards from the target. Evaluators that do not implement it are
+// searched backwards using their neighbors.
+type PredecessorEvaluator interface {
+	// PredecessorCandidates returns the nodes that may have the node passed as neighbor. Each candidate is
+	// checked using Neighbor, so more nodes than needed may be returned.
+	PredecessorCandidates(node *Node) []*Node
+	// Neighbor returns the node at pos as returned by Neighbors of the node passed, or nil if Neighbors would
+	// not return it. Only the move to pos needs to be checked.
+	Neighbor(node *Node, pos cube.Pos) *Node
+}
+
+// FindPathBidirectional builds a pathfind.Path like FindPathContext, but searches from the start and from the
+// target at the same time until both searches meet. It gives up early if the target is enclosed, as the
+// search from the target is exhausted quickly. Nodes are only reached backwards through moves the evaluator
+// allows forwards, so one way moves such as falls and limited step ups are handled correctly.
+func FindPathBidirectional(ctx context.Context, evaluator NodeEvaluator, source world.BlockSource, pos, target cube.Pos, maxVisitedNodes int, maxDistanceFromStart float64, reachRange int, heuristic ...Heuristic) (Result, error) {
+	start := time.Now()
+	evaluator.Prepare(source, pos)
+	defer evaluator.Done()
+
+	h, weight := heuristicOf(heuristic)
+	startNode := evaluator.StartNode()
+	goal := evaluator.Goal(target)
+
+	s := &bidirectionalSearch{
+		evaluator:            evaluator,
+		heuristic:            h,
+		weight:               weight,
+		startNode:            startNode,
+		goal:                 goal,
+		maxDistanceFromStart: maxDistanceFromStart,
+		meeting:              math.Inf(1),
+	}
+	s.forward = newBidirectionalSide(target)
+	s.backward = newBidirectionalSide(startNode.Pos)
+	s.forward.insert(startNode, nil, 0, s.estimate(startNode.Pos, target))
+	for x := -reachRange; x <= reachRange; x++ {
+		for y := -reachRange + Abs(x); y <= reachRange-Abs(x); y++ {
+			for z := -reachRange + Abs(x) + Abs(y); z <= reachRange-Abs(x)-Abs(y); z++ {
+				root := NewNode(target.Add(cube.Pos{x, y, z}))
+				s.backward.insert(root, nil, 0, s.estimate(root.Pos, startNode.Pos))
+			}
+		}
+	}
+	s.visit(s.forward, s.forward.nodes[startNode.Pos])
+
+	result := s.run(ctx.Done(), maxVisitedNodes)
+	result.Elapsed = time.Since(start)
+	if result.Reason == TerminationCancelled {
+		return result, fmt.Errorf("%w: %w", ErrCancelled, ctx.Err())
+	}
+	return result, result.Reason.Err()
+}
+
+// bidirectionalSearch is an A* search from both the start and the target of a path.
+type bidirectionalSearch struct {
+	evaluator NodeEvaluator
+	heuristic Heuristic
+	weight    float64
+
+	startNode *Node
+	goal      *Target
+
+	forward, backward *bidirectionalSide
+
+	maxDistanceFromStart float64
+	distanceLimited      bool
+
+	meeting     float64
+	meetingNode *bidirectionalNode
+}
+
+// run alternates between both sides of the search until they meet, one of them is exhausted, the node budget
+// is used up or done is closed.
+func (s *bidirectionalSearch) run(done <-chan struct{}, maxVisitedNodes int) Result {
+	var result Result
+	for {
+		select {
+		case <-done:
+			return s.result(result, TerminationCancelled)
+		default:
+		}
+		if s.forward.open.Len() == 0 || s.backward.open.Len() == 0 {
+			if s.meetingNode != nil {
+				return s.result(result, TerminationReached)
+			}
+			if s.distanceLimited {
+				return s.result(result, TerminationDistanceLimit)
+			}
+			return s.result(result, TerminationExhausted)
+		}
+		if s.meetingNode != nil && max(s.forward.open.Peek().f, s.backward.open.Peek().f) >= s.meeting {
+			return s.result(result, TerminationReached)
+		}
+		result.VisitedNodes++
+		if result.VisitedNodes >= maxVisitedNodes {
+			return s.result(result, TerminationNodeBudget)
+		}
+
+		side, expand := s.forward, s.expandForward
+		if s.backward.open.Len() < s.forward.open.Len() {
+			side, expand = s.backward, s.expandBackward
+		}
+		current := side.open.Pop()
+		current.closed = true
+		expand(current)
+	}
+}
+
+// expandForward adds the neighbors of the node to the forward side of the search.
+func (s *bidirectionalSearch) expandForward(current *bidirectionalNode) {
+	for _, neighbor := range s.evaluator.Neighbors(current.node) {
+		s.relax(s.forward, neighbor, current, nil, current.node.distance(neighbor)+neighbor.CostMalus)
+	}
+}
+
+// expandBackward adds the nodes that have the node as neighbor to the backward side of the search.
+func (s *bidirectionalSearch) expandBackward(current *bidirectionalNode) {
+	predecessors, ok := s.evaluator.(PredecessorEvaluator)
+	if !ok {
+		// The neighbors are cloned as evaluators may reuse the slice in the next call.
+		for _, candidate := range slices.Clone(s.evaluator.Neighbors(current.node)) {
+			neighbors := s.evaluator.Neighbors(candidate)
+			if index := slices.IndexFunc(neighbors, func(n *Node) bool { return n.Pos == current.node.Pos }); index >= 0 {
+				node := neighbors[index]
+				s.relax(s.backward, candidate, current, node, candidate.distance(node)+node.CostMalus)
+			}
+		}
+		return
+	}
+	for _, candidate := range predecessors.PredecessorCandidates(current.node) {
+		if node := predecessors.Neighbor(candidate, current.node.Pos); node != nil {
+			s.relax(s.backward, candidate, current, node, candidate.distance(node)+node.CostMalus)
+		}
+	}
+}
+
+// relax updates the cost of reaching the node through the node passed on one side of the search. On the
+// backward side, via is the node of from as returned by the evaluator when moving there from the node, which
+// is used in the path instead, as the roots of the backward side are not nodes of the evaluator.
+func (s *bidirectionalSearch) relax(side *bidirectionalSide, node *Node, from *bidirectionalNode, via *Node, cost float64) {
+	if node.distanceSquared(s.startNode) >= s.maxDistanceFromStart*s.maxDistanceFromStart {
+		s.distanceLimited = true
+		return
+	}
+	g := from.g + cost
+	state, ok := side.nodes[node.Pos]
+	switch {
+	case !ok:
+		state = side.insert(node, from, g, g+s.estimate(node.Pos, side.goal))
+	case state.closed || g >= state.g:
+		return
+	default:
+		state.cameFrom, state.f, state.g = from, state.f-state.g+g, g
+		side.open.Fix(state.index)
+	}
+	state.via = via
+	s.visit(side, state)
+}
+
+// visit records the node as meeting point if it was reached from both sides for a lower cost than before.
+func (s *bidirectionalSearch) visit(side *bidirectionalSide, state *bidirectionalNode) {
+	if side == s.forward {
+		s.goal.UpdateBest(s.heuristic.Estimate(state.node.Pos, s.goal.Pos), state.node)
+	}
+	other := s.backward
+	if side == s.backward {
+		other = s.forward
+	}
+	if match, ok := other.nodes[state.node.Pos]; ok && state.g+match.g < s.meeting {
+		s.meeting = state.g + match.g
+		s.meetingNode = s.forward.nodes[state.node.Pos]
+	}
+}
+
+// estimate returns the weighted heuristic from one position to another.
+func (s *bidirectionalSearch) estimate(from, to cube.Pos) float64 {
+	return s.heuristic.Estimate(from, to) * s.weight
+}
+
+// result builds the Result of the search.
+func (s *bidirectionalSearch) result(result Result, reason TerminationReason) Result {
+	result.Reason = reason
+
+	var nodes []*Node
+	if reason == TerminationReached {
+		for current := s.meetingNode; current.cameFrom != nil; current = current.cameFrom {
+			nodes = append(nodes, current.node)
+		}
+		slices.Reverse(nodes)
+		for current := s.backward.nodes[s.meetingNode.node.Pos]; current.cameFrom != nil; current = current.cameFrom {
+			nodes = append(nodes, current.via)
+		}
+		s.goal.SetReached(true)
+	} else {
+		for current := s.forward.nodes[s.goal.BestNode().Pos]; current.cameFrom != nil; current = current.cameFrom {
+			nodes = append(nodes, current.node)
+		}
+		slices.Reverse(nodes)
+	}
+	target := *s.goal
+	result.Target = &target
+	result.Path = NewPath(detachNodes(nodes), reason == TerminationReached, s.goal.Pos)
+	return result
+}
+
+// bidirectionalSide is one side of a bidirectional search.
+type bidirectionalSide struct {
+	nodes map[cube.Pos]*bidirectionalNode
+	open  *Heap[*bidirectionalNode]
+	goal  cube.Pos
+}
+
+// newBidirectionalSide returns a side of a bidirectional search heading to the goal passed.
+func newBidirectionalSide(goal cube.Pos) *bidirectionalSide {
+	open := NewHeap(func(a, b *bidirectionalNode) bool {
+		return a.f < b.f
+	}, func(node *bidirectionalNode, index int) {
+		node.index = index
+	})
+	return &bidirectionalSide{nodes: map[cube.Pos]*bidirectionalNode{}, open: open, goal: goal}
+}
+
+// insert adds a node to the side and its open set.
+func (side *bidirectionalSide) insert(node *Node, cameFrom *bidirectionalNode, g, f float64) *bidirectionalNode {
+	state := &bidirectionalNode{node: node, g: g, f: f, cameFrom: cameFrom}
+	side.nodes[node.Pos] = state
+	side.open.Push(state)
+	return state
+}
+
+// bidirectionalNode is the state of a Node on one side of a bidirectional search.
+type bidirectionalNode struct {
+	node     *Node
+	g, f     float64
+	cameFrom *bidirectionalNode
+	// via is the node of cameFrom as reached from node, only set on the backward side.
+	via    *Node
+	closed bool
+	index  int
+}
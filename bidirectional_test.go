@@ -0,0 +1,133 @@
+package pathfind_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	pathfind "github.com/FDUTCH/Pathfinder"
+	"github.com/FDUTCH/Pathfinder/evaluator"
+	"github.com/FDUTCH/Pathfinder/internal/testworld"
+	"github.com/df-mc/dragonfly/server/block/cube"
+)
+
+// cliff is a ledge two blocks high as wide as the world, which can be fallen down but not climbed.
+var cliff = []string{
+	"#######\n#######\n#######",
+	"...####\n...####\n...####",
+	"...####\n...####\n...####",
+	".......\n.......\n.......",
+	".......\n.......\n.......",
+}
+
+// pool is a floor with a pool of water two blocks deep in the middle.
+var pool = []string{
+	floor,
+	"#######\n#######\n##~~~##\n##~~~##\n##~~~##\n#######\n#######",
+	"#######\n#######\n##~~~##\n##~~~##\n##~~~##\n#######\n#######",
+	open,
+	open,
+}
+
+// neighborsOnly hides the PredecessorEvaluator implementation of an evaluator, so that it is searched
+// backwards using its neighbors.
+type neighborsOnly struct {
+	pathfind.NodeEvaluator
+}
+
+// amphibious returns an AmphibiousNodeEvaluator for an entity with the box passed.
+func amphibious(box cube.BBox) pathfind.NodeEvaluator {
+	e, err := evaluator.AmphibiousNodeEvaluatorConfig{Box: box}.New()
+	if err != nil {
+		panic(err)
+	}
+	return e
+}
+
+func TestFindPathBidirectional(t *testing.T) {
+	box := cube.Box(-0.3, 0, -0.3, 0.3, 1.8, 0.3)
+	tests := []struct {
+		name          string
+		layers        []string
+		start, target cube.Pos
+		evaluator     func() pathfind.NodeEvaluator
+		reachRange    int
+		// oneWay is true if the path needs moves that cannot be reversed, so that evaluators that do not
+		// implement PredecessorEvaluator cannot be searched backwards.
+		oneWay bool
+	}{
+		{name: "straight", layers: []string{floor, open, open}, start: cube.Pos{0, 1, 0}, target: cube.Pos{6, 1, 0}},
+		{name: "around wall", layers: []string{floor, wallWithGap, wallWithGap}, start: cube.Pos{0, 1, 0}, target: cube.Pos{6, 1, 0}},
+		{name: "up stairs", layers: []string{floor, stairs, stairsTop}, start: cube.Pos{0, 1, 0}, target: cube.Pos{6, 3, 3}},
+		{name: "up wide stairs", layers: wideStairs, start: cube.Pos{1, 1, 1}, target: cube.Pos{8, 4, 1}},
+		{name: "down wide stairs", layers: wideStairs, start: cube.Pos{8, 4, 1}, target: cube.Pos{1, 1, 1}},
+		{name: "up wide stairs under ceiling", layers: wideStairsUnderCeiling, start: cube.Pos{1, 1, 1}, target: cube.Pos{8, 4, 1}},
+		{name: "down cliff", layers: cliff, start: cube.Pos{5, 3, 1}, target: cube.Pos{0, 1, 1}, oneWay: true},
+		{name: "up cliff", layers: cliff, start: cube.Pos{0, 1, 1}, target: cube.Pos{5, 3, 1}},
+		{name: "through ground ladder", layers: []string{floor, groundLadder, corridor}, start: cube.Pos{0, 1, 0}, target: cube.Pos{6, 1, 0}},
+		{name: "enclosed", layers: []string{floor, enclosure, enclosure}, start: cube.Pos{0, 1, 0}, target: cube.Pos{5, 1, 5}},
+		{name: "enclosed in reach range", layers: []string{floor, enclosure, enclosure}, start: cube.Pos{0, 1, 0}, target: cube.Pos{5, 1, 5}, reachRange: 2},
+		{name: "closed door", layers: []string{floor, doorway, doorway}, start: cube.Pos{0, 1, 0}, target: cube.Pos{6, 1, 0}},
+		{
+			name:   "closed door opened",
+			layers: []string{floor, doorway, doorway},
+			start:  cube.Pos{0, 1, 0},
+			target: cube.Pos{6, 1, 0},
+			evaluator: func() pathfind.NodeEvaluator {
+				return evaluator.WalkNodeEvaluatorConfig{Box: box, CanPathDoors: true, CanOpenDoors: true}.New()
+			},
+		},
+		{
+			name:   "into pool",
+			layers: pool,
+			start:  cube.Pos{0, 3, 0},
+			target: cube.Pos{3, 1, 3},
+			evaluator: func() pathfind.NodeEvaluator {
+				return amphibious(box)
+			},
+		},
+		{
+			name:   "out of pool",
+			layers: pool,
+			start:  cube.Pos{3, 1, 3},
+			target: cube.Pos{6, 3, 6},
+			evaluator: func() pathfind.NodeEvaluator {
+				return amphibious(box)
+			},
+		},
+	}
+	for _, tt := range tests {
+		if tt.evaluator == nil {
+			tt.evaluator = func() pathfind.NodeEvaluator {
+				return evaluator.WalkNodeEvaluatorConfig{Box: box}.New()
+			}
+		}
+		t.Run(tt.name, func(t *testing.T) {
+			w := testworld.New(tt.layers...)
+			want, wantErr := pathfind.FindPathContext(context.Background(), tt.evaluator(), w, tt.start, tt.target, 10000, 64, tt.reachRange, pathfind.Euclidean)
+			evaluators := []pathfind.NodeEvaluator{tt.evaluator()}
+			if !tt.oneWay {
+				evaluators = append(evaluators, neighborsOnly{tt.evaluator()})
+			}
+			for _, e := range evaluators {
+				got, err := pathfind.FindPathBidirectional(context.Background(), e, w, tt.start, tt.target, 10000, 64, tt.reachRange, pathfind.Euclidean)
+				if !errors.Is(err, wantErr) {
+					t.Fatalf("FindPathBidirectional() with %T error = %v, want %v", e, err, wantErr)
+				}
+				if got.Path.Reached() != want.Path.Reached() {
+					t.Fatalf("Reached() with %T = %v, want %v", e, got.Path.Reached(), want.Path.Reached())
+				}
+				if !want.Path.Reached() {
+					continue
+				}
+				if gotLength, wantLength := pathLength(tt.start, got.Path), pathLength(tt.start, want.Path); math.Abs(gotLength-wantLength) > 1e-9 {
+					t.Errorf("path length with %T = %v, want %v", e, gotLength, wantLength)
+				}
+				if got.Path.EndNode().Pos != want.Path.EndNode().Pos && manhattan(got.Path.EndNode().Pos, tt.target) > tt.reachRange {
+					t.Errorf("EndNode() with %T = %v, want within %v of %v", e, got.Path.EndNode().Pos, tt.reachRange, tt.target)
+				}
+			}
+		})
+	}
+}
@@ -4,138 +4,81 @@ import (
 	"errors"
 )
 
+// TieBreaker reports if node a should be visited before node b when both have the same cost.
+type TieBreaker func(a, b *Node) bool
+
+// PreferLowerHeuristic is a TieBreaker that visits the node estimated closest to the target first, which
+// makes searches dive towards the target through areas of equal cost.
+func PreferLowerHeuristic(a, b *Node) bool {
+	return a.h < b.h
+}
+
+// BinaryHeap is the open set of a search: a Heap of nodes ordered by their cost.
 type BinaryHeap struct {
-	heap map[int]*Node
-	size int
+	heap *Heap[*Node]
 }
 
-func NewBinaryHeap() *BinaryHeap {
-	return &BinaryHeap{
-		heap: make(map[int]*Node),
-		size: 0,
+// NewBinaryHeap returns an empty BinaryHeap. An optional TieBreaker may be passed to order nodes of the same
+// cost.
+func NewBinaryHeap(tieBreaker ...TieBreaker) *BinaryHeap {
+	less := func(a, b *Node) bool {
+		return a.f < b.f
+	}
+	if len(tieBreaker) > 0 && tieBreaker[0] != nil {
+		breakTie := tieBreaker[0]
+		less = func(a, b *Node) bool {
+			return a.f < b.f || a.f == b.f && breakTie(a, b)
+		}
 	}
+	return &BinaryHeap{heap: NewHeap(less, func(node *Node, index int) {
+		node.heapIdx = index
+	})}
 }
 
 func (b *BinaryHeap) Insert(node *Node) (*Node, error) {
 	if node.OpenSet() {
 		return nil, errors.New("node is already in the heap")
 	}
-
-	b.heap[b.size] = node
-	node.heapIdx = b.size
-	b.upHeap(b.size)
-	b.size++
+	b.heap.Push(node)
 	return node, nil
 }
 
 func (b *BinaryHeap) Clear() {
-	b.size = 0
+	b.heap.Clear()
 }
 
 func (b *BinaryHeap) Peek() *Node {
-	if b.size == 0 {
+	if b.heap.Len() == 0 {
 		return nil
 	}
-	return b.heap[0]
+	return b.heap.Peek()
 }
 
 func (b *BinaryHeap) Pop() *Node {
-	if b.size == 0 {
+	if b.heap.Len() == 0 {
 		return nil
 	}
-
-	topNode := b.heap[0]
-	b.size--
-	b.heap[0] = b.heap[b.size]
-	delete(b.heap, b.size)
-	if b.size > 0 {
-		b.downHeap(0)
-	}
-	topNode.heapIdx = -1
-
-	return topNode
+	return b.heap.Pop()
 }
 
 func (b *BinaryHeap) Remove(node *Node) {
-	b.size--
-	lastNode := b.heap[b.size]
-	b.heap[node.heapIdx] = lastNode
-	delete(b.heap, b.size)
-
-	if node.heapIdx < b.size {
-		if lastNode.f < node.f {
-			b.upHeap(node.heapIdx)
-		} else {
-			b.downHeap(node.heapIdx)
-		}
-	}
-	node.heapIdx = -1
+	b.heap.Remove(node.heapIdx)
 }
 
 func (b *BinaryHeap) ChangeCost(node *Node, newCost float64) {
-	oldCost := node.f
 	node.f = newCost
-	if newCost < oldCost {
-		b.upHeap(node.heapIdx)
-	} else {
-		b.downHeap(node.heapIdx)
-	}
+	b.heap.Fix(node.heapIdx)
 }
 
 func (b *BinaryHeap) Size() int {
-	return b.size
-}
-
-func (b *BinaryHeap) upHeap(index int) {
-	node := b.heap[index]
-	for index > 0 {
-		parentIdx := (index - 1) >> 1
-		parent := b.heap[parentIdx]
-		if !(node.f < parent.f) {
-			break
-		}
-
-		b.heap[index] = parent
-		parent.heapIdx = index
-		index = parentIdx
-	}
-	b.heap[index] = node
-	node.heapIdx = index
-}
-
-func (b *BinaryHeap) downHeap(index int) {
-	node := b.heap[index]
-	currentCost := node.f
-
-	for {
-		left := (index << 1) + 1
-		right := left + 1
-		if left >= b.size {
-			break
-		}
-
-		minChild := left
-		if right < b.size && b.heap[right].f < b.heap[left].f {
-			minChild = right
-		}
-
-		if b.heap[minChild].f >= currentCost {
-			break
-		}
-
-		b.heap[index] = b.heap[minChild]
-		b.heap[index].heapIdx = index
-		index = minChild
-	}
-
-	b.heap[index] = node
-	node.heapIdx = index
+	return b.heap.Len()
 }
 
 func (b *BinaryHeap) IsEmpty() bool {
-	return b.size == 0
+	return b.heap.Len() == 0
 }
 
-func (b *BinaryHeap) GetHeap() map[int]*Node {
-	return b.heap
+// GetHeap returns the nodes in the heap in heap order. The slice is only valid until the heap is changed.
+func (b *BinaryHeap) GetHeap() []*Node {
+	return b.heap.Items()
 }
@@ -0,0 +1,176 @@
+package pathfind
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/df-mc/dragonfly/server/block/cube"
+)
+
+// benchmarkNodes returns nodes with random costs for heap benchmarks.
+func benchmarkNodes(n int) []*Node {
+	r := rand.New(rand.NewSource(1))
+	nodes := make([]*Node, n)
+	for i := range nodes {
+		nodes[i] = NewNode(cube.Pos{i, 0, 0})
+		nodes[i].f = r.Float64() * 100
+	}
+	return nodes
+}
+
+// mapBinaryHeap is the map-backed BinaryHeap that BinaryHeap replaced, kept to compare against.
+type mapBinaryHeap struct {
+	heap map[int]*Node
+	size int
+}
+
+func (b *mapBinaryHeap) Insert(node *Node) {
+	b.heap[b.size] = node
+	node.heapIdx = b.size
+	b.upHeap(b.size)
+	b.size++
+}
+
+func (b *mapBinaryHeap) Pop() *Node {
+	topNode := b.heap[0]
+	b.size--
+	b.heap[0] = b.heap[b.size]
+	delete(b.heap, b.size)
+	if b.size > 0 {
+		b.downHeap(0)
+	}
+	topNode.heapIdx = -1
+	return topNode
+}
+
+func (b *mapBinaryHeap) ChangeCost(node *Node, newCost float64) {
+	oldCost := node.f
+	node.f = newCost
+	if newCost < oldCost {
+		b.upHeap(node.heapIdx)
+	} else {
+		b.downHeap(node.heapIdx)
+	}
+}
+
+func (b *mapBinaryHeap) IsEmpty() bool {
+	return b.size == 0
+}
+
+func (b *mapBinaryHeap) upHeap(index int) {
+	node := b.heap[index]
+	for index > 0 {
+		parentIdx := (index - 1) >> 1
+		parent := b.heap[parentIdx]
+		if !(node.f < parent.f) {
+			break
+		}
+		b.heap[index] = parent
+		parent.heapIdx = index
+		index = parentIdx
+	}
+	b.heap[index] = node
+	node.heapIdx = index
+}
+
+func (b *mapBinaryHeap) downHeap(index int) {
+	node := b.heap[index]
+	for {
+		left := (index << 1) + 1
+		right := left + 1
+		if left >= b.size {
+			break
+		}
+		minChild := left
+		if right < b.size && b.heap[right].f < b.heap[left].f {
+			minChild = right
+		}
+		if b.heap[minChild].f >= node.f {
+			break
+		}
+		b.heap[index] = b.heap[minChild]
+		b.heap[index].heapIdx = index
+		index = minChild
+	}
+	b.heap[index] = node
+	node.heapIdx = index
+}
+
+// nodeHeap is the part of BinaryHeap shared with mapBinaryHeap.
+type nodeHeap interface {
+	Pop() *Node
+	ChangeCost(node *Node, newCost float64)
+	IsEmpty() bool
+}
+
+// heapWorkload inserts the nodes into the heap, lowers the cost of every fourth node and pops all nodes,
+// appending their costs to popped in the order popped.
+func heapWorkload(h nodeHeap, insert func(*Node), nodes []*Node, costs []float64, popped []float64) []float64 {
+	for j, node := range nodes {
+		node.f = costs[j]
+		insert(node)
+	}
+	for j := 0; j < len(nodes); j += 4 {
+		h.ChangeCost(nodes[j], nodes[j].f/2)
+	}
+	for !h.IsEmpty() {
+		popped = append(popped, h.Pop().f)
+	}
+	return popped
+}
+
+func TestBinaryHeapMatchesMapBinaryHeap(t *testing.T) {
+	nodes := benchmarkNodes(257)
+	costs := make([]float64, len(nodes))
+	for i, node := range nodes {
+		costs[i] = node.f
+	}
+	h := NewBinaryHeap()
+	got := heapWorkload(h, func(n *Node) { h.Insert(n) }, nodes, costs, nil)
+	old := &mapBinaryHeap{heap: map[int]*Node{}}
+	want := heapWorkload(old, old.Insert, nodes, costs, nil)
+	if !slices.Equal(got, want) {
+		t.Errorf("BinaryHeap popped %v, map-backed heap popped %v", got, want)
+	}
+	if !slices.IsSorted(got) {
+		t.Errorf("BinaryHeap popped %v, want sorted costs", got)
+	}
+	for _, node := range nodes {
+		if node.OpenSet() {
+			t.Errorf("node %v is still in the open set", node.Pos)
+		}
+	}
+}
+
+func BenchmarkBinaryHeap(b *testing.B) {
+	nodes := benchmarkNodes(1024)
+	costs := make([]float64, len(nodes))
+	for i, node := range nodes {
+		costs[i] = node.f
+	}
+	h := NewBinaryHeap()
+	insert := func(n *Node) { h.Insert(n) }
+	popped := make([]float64, 0, len(nodes))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		heapWorkload(h, insert, nodes, costs, popped)
+	}
+}
+
+// BenchmarkMapBinaryHeap runs the workload of BenchmarkBinaryHeap on the map-backed heap BinaryHeap replaced.
+func BenchmarkMapBinaryHeap(b *testing.B) {
+	nodes := benchmarkNodes(1024)
+	costs := make([]float64, len(nodes))
+	for i, node := range nodes {
+		costs[i] = node.f
+	}
+	h := &mapBinaryHeap{heap: map[int]*Node{}}
+	popped := make([]float64, 0, len(nodes))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		heapWorkload(h, h.Insert, nodes, costs, popped)
+	}
+}
@@ -0,0 +1,86 @@
+package pathfind
+
+import (
+	"github.com/df-mc/dragonfly/server/block"
+	"github.com/df-mc/dragonfly/server/block/cube"
+	"github.com/df-mc/dragonfly/server/world"
+	"github.com/df-mc/dragonfly/server/world/particle"
+	"github.com/df-mc/dragonfly/server/world/sound"
+)
+
+// DefaultDoorBreakTicks is the amount of ticks breaking a door takes if DoorInteractor.BreakTicks is zero,
+// which is how long zombies take on hard difficulty.
+const DefaultDoorBreakTicks = 240
+
+// DoorInteractor is an Interactor that opens and breaks wooden doors and opens fence gates. It keeps the
+// progress of breaking a door, so each PathFollower needs its own DoorInteractor.
+type DoorInteractor struct {
+	// BreakTicks is the amount of ticks breaking a door takes. DefaultDoorBreakTicks is used if it is zero.
+	BreakTicks int
+
+	breaking  cube.Pos
+	breakTick int
+}
+
+// Interact opens or breaks the wooden door or opens the fence gate at the node depending on its Action. Nodes
+// without a closed door or gate can be entered right away.
+func (d *DoorInteractor) Interact(tx *world.Tx, ent world.Entity, node *Node) bool {
+	switch b := tx.Block(node.Pos).(type) {
+	case block.WoodDoor:
+		if b.Open {
+			return true
+		}
+		switch node.Action {
+		case ActionOpenDoor:
+			b.Activate(node.Pos, cube.FaceUp, tx, nil, nil)
+		case ActionBreakDoor:
+			return d.breakDoor(tx, node.Pos, b)
+		}
+	case block.WoodFenceGate:
+		if !b.Open && node.Action == ActionOpenGate {
+			openGate(tx, ent, node.Pos, b)
+		}
+	}
+	return true
+}
+
+// openGate opens the fence gate at the position passed away from the entity.
+func openGate(tx *world.Tx, ent world.Entity, pos cube.Pos, gate block.WoodFenceGate) {
+	gate.Open = true
+	if gate.Facing.Opposite() == ent.Rotation().Direction() {
+		gate.Facing = gate.Facing.Opposite()
+	}
+	tx.SetBlock(pos, gate, nil)
+	tx.PlaySound(pos.Vec3Centre(), sound.FenceGateOpen{Block: gate})
+}
+
+// breakDoor progresses breaking the door at the position passed and reports if it was broken.
+func (d *DoorInteractor) breakDoor(tx *world.Tx, pos cube.Pos, door block.WoodDoor) bool {
+	if d.breaking != pos {
+		d.breaking, d.breakTick = pos, 0
+	}
+	d.breakTick++
+	breakTicks := d.BreakTicks
+	if breakTicks <= 0 {
+		breakTicks = DefaultDoorBreakTicks
+	}
+	if d.breakTick < breakTicks {
+		if d.breakTick%20 == 0 {
+			tx.AddParticle(pos.Vec3Centre(), particle.PunchBlock{Block: door, Face: cube.FaceUp})
+		}
+		return false
+	}
+
+	d.breakTick = 0
+	other := pos.Side(cube.FaceUp)
+	if door.Top {
+		other = pos.Side(cube.FaceDown)
+	}
+	if _, ok := tx.Block(other).(block.WoodDoor); ok {
+		tx.SetBlock(other, nil, nil)
+	}
+	tx.SetBlock(pos, nil, nil)
+	tx.PlaySound(pos.Vec3Centre(), sound.DoorCrash{})
+	tx.AddParticle(pos.Vec3Centre(), particle.BlockBreak{Block: door})
+	return true
+}
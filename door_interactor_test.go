@@ -0,0 +1,105 @@
+package pathfind_test
+
+import (
+	"sync"
+	"testing"
+	_ "unsafe"
+
+	pathfind "github.com/FDUTCH/Pathfinder"
+	"github.com/df-mc/dragonfly/server/block"
+	"github.com/df-mc/dragonfly/server/block/cube"
+	"github.com/df-mc/dragonfly/server/world"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// finaliseBlockRegistry finalises the blocks registered in the world package, which is otherwise done by
+// server.New. Blocks can only be placed in a world.World once it was called.
+//
+//go:linkname finaliseBlockRegistry github.com/df-mc/dragonfly/server/world.finaliseBlockRegistry
+func finaliseBlockRegistry()
+
+var finaliseOnce sync.Once
+
+// newWorld returns an empty world.World in which blocks can be placed.
+func newWorld() *world.World {
+	finaliseOnce.Do(finaliseBlockRegistry)
+	return world.Config{}.New()
+}
+
+func TestDoorInteractor(t *testing.T) {
+	door := cube.Pos{2, 1, 0}
+	tests := []struct {
+		name       string
+		block      world.Block
+		action     pathfind.NodeAction
+		breakTicks int
+		// wantTicks is the amount of ticks until the follower moves into the node.
+		wantTicks int
+		// want checks the block at the node and above it once the follower moves.
+		want func(b, above world.Block) bool
+	}{
+		{
+			name:      "open door",
+			block:     block.WoodDoor{},
+			action:    pathfind.ActionOpenDoor,
+			wantTicks: 1,
+			want: func(b, above world.Block) bool {
+				bottom, ok := b.(block.WoodDoor)
+				top, topOk := above.(block.WoodDoor)
+				return ok && topOk && bottom.Open && top.Open
+			},
+		},
+		{
+			name:       "break door",
+			block:      block.WoodDoor{},
+			action:     pathfind.ActionBreakDoor,
+			breakTicks: 3,
+			wantTicks:  3,
+			want: func(b, above world.Block) bool {
+				_, air := b.(block.Air)
+				_, airAbove := above.(block.Air)
+				return air && airAbove
+			},
+		},
+		{
+			name:      "open gate",
+			block:     block.WoodFenceGate{},
+			action:    pathfind.ActionOpenGate,
+			wantTicks: 1,
+			want: func(b, _ world.Block) bool {
+				gate, ok := b.(block.WoodFenceGate)
+				return ok && gate.Open
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newWorld()
+			defer w.Close()
+
+			p := line(1, 2, 3)
+			p.Node(1).Action = tt.action
+			f := pathfind.PathFollowerConfig{Speed: 0.25, Interactor: &pathfind.DoorInteractor{BreakTicks: tt.breakTicks}}.New(p)
+			ent := newWalker(mgl64.Vec3{1.5, 1, 0.5})
+
+			<-w.Exec(func(tx *world.Tx) {
+				tx.SetBlock(door, tt.block, nil)
+				if _, ok := tt.block.(block.WoodDoor); ok {
+					tx.SetBlock(door.Side(cube.FaceUp), block.WoodDoor{Top: true}, nil)
+				}
+				for tick := 1; tick <= tt.wantTicks; tick++ {
+					m := f.Tick(tx, ent)
+					if tick < tt.wantTicks && m.State != pathfind.FollowStateInteracting {
+						t.Fatalf("State at tick %v = %v, want %v", tick, m.State, pathfind.FollowStateInteracting)
+					}
+					if tick == tt.wantTicks && m.State != pathfind.FollowStateMoving {
+						t.Fatalf("State at tick %v = %v, want %v", tick, m.State, pathfind.FollowStateMoving)
+					}
+				}
+				if b, above := tx.Block(door), tx.Block(door.Side(cube.FaceUp)); !tt.want(b, above) {
+					t.Errorf("blocks after interacting = %v, %v", b, above)
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package evaluator
+
+import (
+	"errors"
+	"github.com/FDUTCH/Pathfinder"
+	"github.com/FDUTCH/Pathfinder/path"
+	"github.com/df-mc/dragonfly/server/block"
+	"github.com/df-mc/dragonfly/server/block/cube"
+	"github.com/df-mc/dragonfly/server/world"
+	"github.com/go-gl/mathgl/mgl64"
+	"golang.org/x/exp/maps"
+)
+
+type AmphibiousNodeEvaluatorConfig struct {
+	CostMap           path.CostMap
+	Box               cube.BBox
+	Pos               mgl64.Vec3
+	CanPathDoors      bool
+	CanOpenDoors      bool
+	CanWalkOverFences bool
+	MaxStepUp         float64
+	MaxFallDistance   int
+	// LandCostMultiplier and WaterCostMultiplier scale the cost of moving a block on land and through water
+	// respectively, which is the distance moved plus the cost malus of the path type. They default to 1.
+	// Only their ratio matters: moves in the medium with the lower multiplier keep their cost, so that no move
+	// costs less than its distance. New returns ErrInvalidCostMultiplier if one is negative.
+	LandCostMultiplier, WaterCostMultiplier float64
+	// Cache is an optional PathTypeCache shared with other evaluators.
+	Cache *PathTypeCache
+}
+
+// ErrInvalidCostMultiplier is returned by AmphibiousNodeEvaluatorConfig.New if a cost multiplier is negative.
+var ErrInvalidCostMultiplier = errors.New("evaluator: amphibious cost multipliers must be above 0")
+
+func (c AmphibiousNodeEvaluatorConfig) New() (*AmphibiousNodeEvaluator, error) {
+	if c.LandCostMultiplier == 0 {
+		c.LandCostMultiplier = 1
+	}
+	if c.WaterCostMultiplier == 0 {
+		c.WaterCostMultiplier = 1
+	}
+	if !(c.LandCostMultiplier > 0) || !(c.WaterCostMultiplier > 0) {
+		return nil, ErrInvalidCostMultiplier
+	}
+	lowest := min(c.LandCostMultiplier, c.WaterCostMultiplier)
+
+	costMap := maps.Clone(c.CostMap)
+	if costMap == nil {
+		costMap = path.CostMap{}
+	}
+	if _, has := costMap[path.WATER]; !has {
+		costMap.SetPathfindingMalus(path.WATER, 0)
+	}
+
+	walk := WalkNodeEvaluatorConfig{
+		CostMap:           costMap,
+		Box:               c.Box,
+		Pos:               c.Pos,
+		CanPathDoors:      c.CanPathDoors,
+		CanOpenDoors:      c.CanOpenDoors,
+		CanFloat:          true,
+		CanWalkOverFences: c.CanWalkOverFences,
+		MaxStepUp:         c.MaxStepUp,
+		MaxFallDistance:   c.MaxFallDistance,
+		Cache:             c.Cache,
+	}.New()
+	walk.classify = AmphibiousBlockPathType
+	walk.kind = kindAmphibious
+
+	e := &AmphibiousNodeEvaluator{
+		WalkNodeEvaluator: walk,
+		landCost:          c.LandCostMultiplier / lowest,
+		waterCost:         c.WaterCostMultiplier / lowest,
+	}
+	walk.scaleMalus = e.scaleMalus
+	return e, nil
+}
+
+// AmphibiousNodeEvaluator implements pathfind.NodeEvaluator for entities that walk on land and dive through
+// water. Nodes on land follow the rules of WalkNodeEvaluator, nodes in water may also move up and down.
+type AmphibiousNodeEvaluator struct {
+	*WalkNodeEvaluator
+
+	// landCost and waterCost are the factors the cost of moving a block on land and through water are
+	// multiplied by. The lower of the two is 1.
+	landCost, waterCost float64
+}
+
+func (e *AmphibiousNodeEvaluator) StartNode() *pathfind.Node {
+	if _, water := e.source.Block(e.startPosition).(block.Water); !water {
+		return e.WalkNodeEvaluator.StartNode()
+	}
+	return e.startNode(e.startPosition)
+}
+
+func (e *AmphibiousNodeEvaluator) Neighbors(node *pathfind.Node) []*pathfind.Node {
+	nodes := e.WalkNodeEvaluator.Neighbors(node)
+	o := e.originOf(node)
+	for _, face := range []cube.Face{cube.FaceUp, cube.FaceDown} {
+		if n := e.verticalNeighbor(node, o, face); n != nil {
+			nodes = append(nodes, n)
+		}
+	}
+	e.neighbors = nodes
+	return nodes
+}
+
+// Neighbor returns the neighbor of the node at pos like Neighbors, or nil if the node has no neighbor there.
+func (e *AmphibiousNodeEvaluator) Neighbor(node *pathfind.Node, pos cube.Pos) *pathfind.Node {
+	if n := e.WalkNodeEvaluator.Neighbor(node, pos); n != nil {
+		return n
+	}
+	switch pos {
+	case node.Side(cube.FaceUp):
+		return e.verticalNeighbor(node, e.originOf(node), cube.FaceUp)
+	case node.Side(cube.FaceDown):
+		return e.verticalNeighbor(node, e.originOf(node), cube.FaceDown)
+	}
+	return nil
+}
+
+// scaleMalus returns the cost malus of the node at the position passed, so that moving to it costs 1 plus the
+// malus of its path type, multiplied by the cost factor of land or water.
+func (e *AmphibiousNodeEvaluator) scaleMalus(pos cube.Pos, malus float64) float64 {
+	multiplier := e.landCost
+	if _, water := e.source.Block(pos).(block.Water); water {
+		multiplier = e.waterCost
+	}
+	return (malus+1)*multiplier - 1
+}
+
+// verticalNeighbor returns the node the entity swims to from the node in the direction of the face passed, or
+// nil if it cannot swim there.
+func (e *AmphibiousNodeEvaluator) verticalNeighbor(node *pathfind.Node, o origin, face cube.Face) *pathfind.Node {
+	maxUpStep := o.maxUpStep
+	if face == cube.FaceUp {
+		maxUpStep = max(0, maxUpStep-1)
+	}
+	n := e.AcceptedNode(node.Side(face), maxUpStep, o.floorLevel, face, o.pathType)
+	if !e.isVerticalNeighborValid(n, node) || face == cube.FaceDown && o.pathType == path.TRAPDOOR {
+		return nil
+	}
+	return n
+}
+
+// PredecessorCandidates returns the nodes the entity may walk, fall, climb or swim to the node from.
+func (e *AmphibiousNodeEvaluator) PredecessorCandidates(node *pathfind.Node) []*pathfind.Node {
+	nodes := e.WalkNodeEvaluator.PredecessorCandidates(node)
+	for _, face := range []cube.Face{cube.FaceUp, cube.FaceDown} {
+		if pos := node.Side(face); e.CachedBlockPathType(e.source, pos) == path.WATER {
+			nodes = append(nodes, e.Node(pos))
+		}
+	}
+	return nodes
+}
+
+// isVerticalNeighborValid checks if the neighbor above or below the node passed can be swum to.
+func (e *AmphibiousNodeEvaluator) isVerticalNeighborValid(neighbor, node *pathfind.Node) bool {
+	return neighbor != nil && neighbor.Type == path.WATER && e.IsNeighborValid(neighbor, node)
+}
+
+// AmphibiousBlockPathType returns path.BlockPathType for passed position for amphibious entities. Water next
+// to a blocked position is path.WATER_BORDER, everything else is classified like BlockPathType.
+func AmphibiousBlockPathType(source world.BlockSource, pos cube.Pos) path.BlockPathType {
+	pathType := BlockPathTypeRaw(source, pos)
+	if pathType != path.WATER {
+		return BlockPathType(source, pos)
+	}
+	for _, face := range cube.Faces() {
+		if BlockPathTypeRaw(source, pos.Side(face)) == path.BLOCKED {
+			return path.WATER_BORDER
+		}
+	}
+	return path.WATER
+}
@@ -0,0 +1,141 @@
+package evaluator_test
+
+import (
+	"errors"
+	"testing"
+
+	pathfind "github.com/FDUTCH/Pathfinder"
+	"github.com/FDUTCH/Pathfinder/evaluator"
+	"github.com/FDUTCH/Pathfinder/internal/testworld"
+	"github.com/FDUTCH/Pathfinder/path"
+	"github.com/df-mc/dragonfly/server/block"
+	"github.com/df-mc/dragonfly/server/block/cube"
+)
+
+// amphibiousNeighbors returns the neighbors of the node at the position passed by position.
+func amphibiousNeighbors(w *testworld.World, config evaluator.AmphibiousNodeEvaluatorConfig, pos cube.Pos) map[cube.Pos]*pathfind.Node {
+	config.Box = playerBox
+	e, err := config.New()
+	if err != nil {
+		panic(err)
+	}
+	e.Prepare(w, pos)
+	defer e.Done()
+
+	nodes := map[cube.Pos]*pathfind.Node{}
+	for _, n := range e.Neighbors(e.Node(pos)) {
+		nodes[n.Pos] = n
+	}
+	return nodes
+}
+
+func TestAmphibiousNodeEvaluatorCostMultipliers(t *testing.T) {
+	// A pool two blocks deep next to a bank, and a field with a cactus.
+	pool := testworld.New()
+	pool.Fill(cube.Pos{0, 0, 0}, cube.Pos{5, 0, 4}, block.Stone{})
+	pool.Fill(cube.Pos{0, 1, 0}, cube.Pos{1, 2, 4}, block.Stone{})
+	pool.Fill(cube.Pos{2, 1, 0}, cube.Pos{5, 2, 4}, block.Water{Still: true, Depth: 8})
+	field := testworld.New(flatFloor, middleRow("....C"))
+
+	tests := []struct {
+		name        string
+		land, water float64
+		// wantLand and wantWater are the factors the cost of moving a block, 1 plus the malus, should be scaled by.
+		wantLand, wantWater float64
+	}{
+		{name: "default", wantLand: 1, wantWater: 1},
+		{name: "equal", land: 2, water: 2, wantLand: 1, wantWater: 1},
+		{name: "cheap land", land: 0.5, water: 2, wantLand: 1, wantWater: 4},
+		{name: "cheap water", land: 3, water: 0.25, wantLand: 12, wantWater: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := evaluator.AmphibiousNodeEvaluatorConfig{
+				CostMap:             path.CostMap{path.WATER: 4},
+				LandCostMultiplier:  tt.land,
+				WaterCostMultiplier: tt.water,
+			}
+
+			scaled := func(malus, factor float64) float64 {
+				return (malus+1)*factor - 1
+			}
+			want := map[cube.Pos]float64{
+				{4, 2, 2}: scaled(4, tt.wantWater),
+				{2, 2, 2}: scaled(path.CostMap{}.PathfindingMalus(path.WATER_BORDER), tt.wantWater),
+			}
+			nodes := amphibiousNeighbors(pool, config, cube.Pos{3, 2, 2})
+			for pos, malus := range want {
+				if n, ok := nodes[pos]; !ok || n.CostMalus != malus {
+					t.Errorf("water neighbor at %v = %v, want cost malus %v", pos, n, malus)
+				}
+			}
+
+			want = map[cube.Pos]float64{
+				{1, 1, 2}: scaled(0, tt.wantLand),
+				{3, 1, 2}: scaled(path.CostMap{}.PathfindingMalus(path.DANGER_OTHER), tt.wantLand),
+			}
+			nodes = amphibiousNeighbors(field, config, cube.Pos{2, 1, 2})
+			for pos, malus := range want {
+				if n, ok := nodes[pos]; !ok || n.CostMalus != malus {
+					t.Errorf("land neighbor at %v = %v, want cost malus %v", pos, n, malus)
+				}
+			}
+		})
+	}
+}
+
+func TestAmphibiousNodeEvaluatorCostMultipliersRoute(t *testing.T) {
+	// A shallow lake north of a path of land that is flooded in the middle. Both are next to water, so
+	// that they have the same path type.
+	w := testworld.New(
+		"#######\n#######\n#######\n#######\n#######",
+		"~~~~~~~\n~~~~~~~\n~~~~~~~\n..~~~..\n.......",
+		".......\n.......\n.......\n.......\n.......",
+		".......\n.......\n.......\n.......\n.......",
+	)
+	start, target := cube.Pos{0, 1, 3}, cube.Pos{6, 1, 3}
+
+	tests := []struct {
+		name        string
+		land, water float64
+		// wantLand and wantWater are the most nodes the path may have on land and in water respectively.
+		wantLand, wantWater int
+	}{
+		{name: "expensive land", land: 50, water: 1, wantLand: 1, wantWater: 7},
+		{name: "expensive water", land: 1, water: 50, wantLand: 8, wantWater: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := evaluator.AmphibiousNodeEvaluatorConfig{
+				Box:                 playerBox,
+				CostMap:             path.CostMap{path.WATER_BORDER: 0},
+				LandCostMultiplier:  tt.land,
+				WaterCostMultiplier: tt.water,
+			}.New()
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			p := pathfind.FindPath(e, w, start, target, 1000, 64, 0, pathfind.Euclidean)
+			if !p.Reached() {
+				t.Fatalf("FindPath() did not reach %v", target)
+			}
+			var land, water int
+			for i := 1; i < p.Count(); i++ {
+				if _, ok := w.Block(p.Node(i).Pos).(block.Water); ok {
+					water++
+				} else {
+					land++
+				}
+			}
+			if land > tt.wantLand || water > tt.wantWater {
+				t.Errorf("path has %v nodes on land and %v in water, want at most %v and %v", land, water, tt.wantLand, tt.wantWater)
+			}
+		})
+	}
+}
+
+func TestAmphibiousNodeEvaluatorNegativeMultiplier(t *testing.T) {
+	if _, err := (evaluator.AmphibiousNodeEvaluatorConfig{Box: playerBox, WaterCostMultiplier: -1}).New(); !errors.Is(err, evaluator.ErrInvalidCostMultiplier) {
+		t.Errorf("New() with a negative multiplier error = %v, want %v", err, evaluator.ErrInvalidCostMultiplier)
+	}
+}
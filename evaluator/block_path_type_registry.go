@@ -0,0 +1,81 @@
+package evaluator
+
+import (
+	"github.com/FDUTCH/Pathfinder/path"
+	"github.com/df-mc/dragonfly/server/world"
+	"golang.org/x/exp/maps"
+	"reflect"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// blockPathTypeRegistry holds the path types registered for blocks. A registry is never changed once it is
+// stored, registering replaces it with an updated copy so that lookups need no locking.
+type blockPathTypeRegistry struct {
+	types map[reflect.Type]path.BlockPathType
+	funcs []blockPathTypeFunc
+}
+
+// blockPathTypeFunc is a predicate registered using RegisterBlockPathTypeFunc.
+type blockPathTypeFunc struct {
+	matches  func(world.Block) bool
+	pathType path.BlockPathType
+}
+
+var (
+	registryMu sync.Mutex
+	registry   atomic.Pointer[blockPathTypeRegistry]
+)
+
+// RegisterBlockPathType makes BlockPathTypeRaw classify all blocks of the same type as the block passed as
+// the path.BlockPathType passed, instead of the built-in classification. Registering a type again replaces
+// its path type. Path types created by path.Register are also given to the position above the block, so that
+// blocks like claimed land are avoided when standing on them. Blocks should be registered before searches
+// start, as path types already cached by a PathTypeCache are not updated.
+func RegisterBlockPathType(b world.Block, pathType path.BlockPathType) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	r := cloneRegistry()
+	r.types[reflect.TypeOf(b)] = pathType
+	registry.Store(r)
+}
+
+// RegisterBlockPathTypeFunc makes BlockPathTypeRaw classify the blocks the function passed returns true for
+// as the path.BlockPathType passed. Functions are only called for blocks of types that were not registered
+// with RegisterBlockPathType, in the order they were registered, and must be safe for concurrent use.
+func RegisterBlockPathTypeFunc(f func(world.Block) bool, pathType path.BlockPathType) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	r := cloneRegistry()
+	r.funcs = append(r.funcs, blockPathTypeFunc{matches: f, pathType: pathType})
+	registry.Store(r)
+}
+
+// cloneRegistry returns a copy of the current registry that may be changed.
+func cloneRegistry() *blockPathTypeRegistry {
+	r := registry.Load()
+	if r == nil {
+		return &blockPathTypeRegistry{types: map[reflect.Type]path.BlockPathType{}}
+	}
+	return &blockPathTypeRegistry{types: maps.Clone(r.types), funcs: slices.Clone(r.funcs)}
+}
+
+// registeredPathType returns the path.BlockPathType registered for the block passed.
+func registeredPathType(bl world.Block) (path.BlockPathType, bool) {
+	r := registry.Load()
+	if r == nil {
+		return 0, false
+	}
+	if pathType, ok := r.types[reflect.TypeOf(bl)]; ok {
+		return pathType, true
+	}
+	for _, f := range r.funcs {
+		if f.matches(bl) {
+			return f.pathType, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,73 @@
+package evaluator_test
+
+import (
+	"testing"
+
+	"github.com/FDUTCH/Pathfinder/evaluator"
+	"github.com/FDUTCH/Pathfinder/internal/testworld"
+	"github.com/FDUTCH/Pathfinder/path"
+	"github.com/df-mc/dragonfly/server/block/cube"
+	"github.com/df-mc/dragonfly/server/block/model"
+	"github.com/df-mc/dragonfly/server/world"
+)
+
+// registeredBlock is a custom block whose path type is registered by type.
+type registeredBlock struct{}
+
+func (registeredBlock) EncodeBlock() (string, map[string]any) { return "pathfinder:registered", nil }
+func (registeredBlock) Hash() (uint64, uint64)                { return 0, 0 }
+func (registeredBlock) Model() world.BlockModel               { return model.Solid{} }
+
+func TestRegisterBlockPathType(t *testing.T) {
+	w := testworld.New()
+	w.Set(cube.Pos{}, registeredBlock{})
+	if got := evaluator.BlockPathTypeRaw(w, cube.Pos{}); got != path.BLOCKED {
+		t.Fatalf("BlockPathTypeRaw() before registering = %v, want %v", got, path.BLOCKED)
+	}
+	evaluator.RegisterBlockPathType(registeredBlock{}, path.DAMAGE_OTHER)
+	if got := evaluator.BlockPathTypeRaw(w, cube.Pos{}); got != path.DAMAGE_OTHER {
+		t.Fatalf("BlockPathTypeRaw() = %v, want %v", got, path.DAMAGE_OTHER)
+	}
+	evaluator.RegisterBlockPathType(registeredBlock{}, path.SLOW)
+	if got := evaluator.BlockPathTypeRaw(w, cube.Pos{}); got != path.SLOW {
+		t.Fatalf("BlockPathTypeRaw() after registering again = %v, want %v", got, path.SLOW)
+	}
+}
+
+// claimedBlock is a solid custom block that is registered with a custom path type.
+type claimedBlock struct{}
+
+func (claimedBlock) EncodeBlock() (string, map[string]any) { return "pathfinder:claimed", nil }
+func (claimedBlock) Hash() (uint64, uint64)                { return 0, 0 }
+func (claimedBlock) Model() world.BlockModel               { return model.Solid{} }
+
+func TestRegisterBlockPathTypeFloor(t *testing.T) {
+	claimed, err := path.Register("CLAIMED_FLOOR", 12, false)
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	evaluator.RegisterBlockPathType(claimedBlock{}, claimed)
+
+	w := testworld.New()
+	w.Set(cube.Pos{}, claimedBlock{})
+	if got := evaluator.BlockPathType(w, cube.Pos{0, 1, 0}); got != claimed {
+		t.Errorf("BlockPathType() above registered floor = %v, want %v", got, claimed)
+	}
+}
+
+func TestRegisterBlockPathTypeFunc(t *testing.T) {
+	evaluator.RegisterBlockPathTypeFunc(func(b world.Block) bool {
+		name, _ := b.EncodeBlock()
+		return name == "pathfinder:acid"
+	}, path.DAMAGE_OTHER)
+
+	w := testworld.New()
+	w.Set(cube.Pos{}, namedBlock("pathfinder:acid"))
+	w.Set(cube.Pos{1, 0, 0}, namedBlock("pathfinder:mud"))
+	if got := evaluator.BlockPathTypeRaw(w, cube.Pos{}); got != path.DAMAGE_OTHER {
+		t.Errorf("BlockPathTypeRaw() of matched block = %v, want %v", got, path.DAMAGE_OTHER)
+	}
+	if got := evaluator.BlockPathTypeRaw(w, cube.Pos{1, 0, 0}); got != path.OPEN {
+		t.Errorf("BlockPathTypeRaw() of unmatched block = %v, want %v", got, path.OPEN)
+	}
+}
@@ -0,0 +1,296 @@
+package evaluator_test
+
+import (
+	"testing"
+
+	"github.com/FDUTCH/Pathfinder/evaluator"
+	"github.com/FDUTCH/Pathfinder/internal/testworld"
+	"github.com/FDUTCH/Pathfinder/path"
+	"github.com/df-mc/dragonfly/server/block"
+	"github.com/df-mc/dragonfly/server/block/cube"
+	"github.com/df-mc/dragonfly/server/block/model"
+	"github.com/df-mc/dragonfly/server/world"
+	"golang.org/x/exp/maps"
+)
+
+// namedBlock is a block without collision that is only known by its name, like custom implementations of
+// blocks Dragonfly does not implement.
+type namedBlock string
+
+func (b namedBlock) EncodeBlock() (string, map[string]any) { return string(b), nil }
+func (b namedBlock) Hash() (uint64, uint64)                { return 0, 0 }
+func (b namedBlock) Model() world.BlockModel               { return model.Empty{} }
+
+func TestBlockPathTypeRaw(t *testing.T) {
+	tests := []struct {
+		name  string
+		block world.Block
+		want  path.BlockPathType
+	}{
+		{"air", block.Air{}, path.OPEN},
+		{"short grass", block.ShortGrass{}, path.OPEN},
+		{"carpet", block.Carpet{}, path.OPEN},
+		{"stone", block.Stone{}, path.BLOCKED},
+		{"slab", block.Slab{Block: block.Stone{}}, path.BLOCKED},
+		{"wood trapdoor", block.WoodTrapdoor{}, path.TRAPDOOR},
+		{"top wood trapdoor", block.WoodTrapdoor{Top: true}, path.BLOCKED},
+		{"open wood trapdoor", block.WoodTrapdoor{Open: true}, path.OPEN},
+		{"copper trapdoor", block.CopperTrapdoor{}, path.TRAPDOOR},
+		{"top copper trapdoor", block.CopperTrapdoor{Top: true}, path.BLOCKED},
+		{"open copper trapdoor", block.CopperTrapdoor{Open: true, Top: true}, path.OPEN},
+		{"cactus", block.Cactus{}, path.DAMAGE_OTHER},
+		{"cocoa bean", block.CocoaBean{}, path.COCOA},
+		{"water", block.Water{Still: true, Depth: 8}, path.WATER},
+		{"lava", block.Lava{Still: true, Depth: 8}, path.LAVA},
+		{"fire", block.Fire{}, path.DAMAGE_FIRE},
+		{"campfire", block.Campfire{}, path.DAMAGE_FIRE},
+		{"extinguished campfire", block.Campfire{Extinguished: true}, path.BLOCKED},
+		{"wither rose", block.Flower{Type: block.WitherRose()}, path.DAMAGE_CAUTIOUS},
+		{"dandelion", block.Flower{Type: block.Dandelion()}, path.OPEN},
+		{"closed wood door", block.WoodDoor{}, path.DOOR_WOOD_CLOSED},
+		{"open wood door", block.WoodDoor{Open: true}, path.DOOR_OPEN},
+		{"closed copper door", block.CopperDoor{}, path.DOOR_IRON_CLOSED},
+		{"open copper door", block.CopperDoor{Open: true}, path.DOOR_OPEN},
+		{"leaves", block.Leaves{}, path.LEAVES},
+		{"ladder", block.Ladder{Facing: cube.North}, path.CLIMBABLE},
+		{"wood fence", block.WoodFence{}, path.FENCE},
+		{"nether brick fence", block.NetherBrickFence{}, path.FENCE},
+		{"wall", block.Wall{Block: block.Cobblestone{}}, path.FENCE},
+		{"closed fence gate", block.WoodFenceGate{}, path.FENCE_GATE_CLOSED},
+		{"open fence gate", block.WoodFenceGate{Open: true}, path.OPEN},
+		{"vine", namedBlock("minecraft:vine"), path.CLIMBABLE},
+		{"stone pressure plate", namedBlock("minecraft:stone_pressure_plate"), path.PRESSURE_PLATE},
+		{"wooden pressure plate", namedBlock("minecraft:wooden_pressure_plate"), path.PRESSURE_PLATE},
+		{"tripwire", namedBlock("minecraft:trip_wire"), path.TRIPWIRE},
+		{"sweet berry bush", namedBlock("minecraft:sweet_berry_bush"), path.DAMAGE_OTHER},
+		{"pointed dripstone", namedBlock("minecraft:pointed_dripstone"), path.DAMAGE_CAUTIOUS},
+		{"cobweb", namedBlock("minecraft:web"), path.SLOW},
+		{"powder snow", namedBlock("minecraft:powder_snow"), path.POWDER_SNOW},
+		{"honey block", namedBlock("minecraft:honey_block"), path.BLOCKED},
+		{"magma", namedBlock("minecraft:magma"), path.BLOCKED},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := testworld.New()
+			w.Set(cube.Pos{}, tt.block)
+			if got := evaluator.BlockPathTypeRaw(w, cube.Pos{}); got != tt.want {
+				t.Errorf("BlockPathTypeRaw() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// legend is the testworld.DefaultLegend with top and open trapdoors, pressure plates, extinguished campfires,
+// cobwebs, tripwires, pointed dripstone and blocks that change the floor path type.
+var legend = func() testworld.Legend {
+	l := maps.Clone(testworld.DefaultLegend)
+	l['t'] = block.WoodTrapdoor{Top: true}
+	l['o'] = block.WoodTrapdoor{Open: true}
+	l['P'] = namedBlock("minecraft:stone_pressure_plate")
+	l['S'] = block.SoulSand{}
+	l['M'] = namedBlock("minecraft:magma")
+	l['h'] = namedBlock("minecraft:honey_block")
+	l['e'] = block.Campfire{Extinguished: true}
+	l['w'] = namedBlock("minecraft:web")
+	l['r'] = namedBlock("minecraft:trip_wire")
+	l['v'] = namedBlock("minecraft:pointed_dripstone")
+	return l
+}()
+
+func TestBlockPathType(t *testing.T) {
+	tests := []struct {
+		name   string
+		layers []string
+		pos    cube.Pos
+		want   path.BlockPathType
+	}{
+		{
+			name:   "above solid block",
+			layers: []string{"#", "."},
+			pos:    cube.Pos{0, 1, 0},
+			want:   path.WALKABLE,
+		},
+		{
+			name:   "above air",
+			layers: []string{".", "."},
+			pos:    cube.Pos{0, 1, 0},
+			want:   path.OPEN,
+		},
+		{
+			name:   "above water",
+			layers: []string{"~", "."},
+			pos:    cube.Pos{0, 1, 0},
+			want:   path.OPEN,
+		},
+		{
+			name:   "above pressure plate",
+			layers: []string{"#", "P", "."},
+			pos:    cube.Pos{0, 2, 0},
+			want:   path.OPEN,
+		},
+		{
+			name:   "above top trapdoor",
+			layers: []string{"t", "."},
+			pos:    cube.Pos{0, 1, 0},
+			want:   path.WALKABLE,
+		},
+		{
+			name:   "above open trapdoor",
+			layers: []string{"o", "."},
+			pos:    cube.Pos{0, 1, 0},
+			want:   path.OPEN,
+		},
+		{
+			name:   "above cactus",
+			layers: []string{"C", "."},
+			pos:    cube.Pos{0, 1, 0},
+			want:   path.DAMAGE_OTHER,
+		},
+		{
+			name:   "above fire",
+			layers: []string{"f", "."},
+			pos:    cube.Pos{0, 1, 0},
+			want:   path.DAMAGE_FIRE,
+		},
+		{
+			name:   "above soul sand",
+			layers: []string{"S", "."},
+			pos:    cube.Pos{0, 1, 0},
+			want:   path.SLOW,
+		},
+		{
+			name:   "above soul sand next to cactus",
+			layers: []string{"SS", ".C"},
+			pos:    cube.Pos{0, 1, 0},
+			want:   path.DANGER_OTHER,
+		},
+		{
+			name:   "above magma",
+			layers: []string{"M", "."},
+			pos:    cube.Pos{0, 1, 0},
+			want:   path.DAMAGE_FIRE,
+		},
+		{
+			name:   "above honey block",
+			layers: []string{"h", "."},
+			pos:    cube.Pos{0, 1, 0},
+			want:   path.STICKY_HONEY,
+		},
+		{
+			name:   "cobweb above solid block",
+			layers: []string{"#", "w"},
+			pos:    cube.Pos{0, 1, 0},
+			want:   path.SLOW,
+		},
+		{
+			name:   "cobweb above air",
+			layers: []string{".", "w"},
+			pos:    cube.Pos{0, 1, 0},
+			want:   path.OPEN,
+		},
+		{
+			name:   "tripwire above solid block",
+			layers: []string{"#", "r"},
+			pos:    cube.Pos{0, 1, 0},
+			want:   path.TRIPWIRE,
+		},
+		{
+			name:   "tripwire above air",
+			layers: []string{".", "r"},
+			pos:    cube.Pos{0, 1, 0},
+			want:   path.OPEN,
+		},
+		{
+			name:   "pressure plate above air",
+			layers: []string{".", "P"},
+			pos:    cube.Pos{0, 1, 0},
+			want:   path.OPEN,
+		},
+		{
+			name:   "pointed dripstone above solid block",
+			layers: []string{"#", "v"},
+			pos:    cube.Pos{0, 1, 0},
+			want:   path.DAMAGE_CAUTIOUS,
+		},
+		{
+			name:   "pointed dripstone above air",
+			layers: []string{".", "v"},
+			pos:    cube.Pos{0, 1, 0},
+			want:   path.OPEN,
+		},
+		{
+			name:   "above cobweb",
+			layers: []string{"#", "w", "."},
+			pos:    cube.Pos{0, 2, 0},
+			want:   path.OPEN,
+		},
+		{
+			name:   "next to extinguished campfire",
+			layers: []string{"##", ".e"},
+			pos:    cube.Pos{0, 1, 0},
+			want:   path.WALKABLE,
+		},
+		{
+			name:   "next to cactus",
+			layers: []string{"##", ".C"},
+			pos:    cube.Pos{0, 1, 0},
+			want:   path.DANGER_OTHER,
+		},
+		{
+			name:   "diagonal to cactus",
+			layers: []string{"##\n##", "..\n.C"},
+			pos:    cube.Pos{0, 1, 0},
+			want:   path.DANGER_OTHER,
+		},
+		{
+			name:   "two blocks from cactus",
+			layers: []string{"###", "..C"},
+			pos:    cube.Pos{0, 1, 0},
+			want:   path.WALKABLE,
+		},
+		{
+			name:   "cactus two blocks below",
+			layers: []string{"C", "#", "."},
+			pos:    cube.Pos{0, 2, 0},
+			want:   path.WALKABLE,
+		},
+		{
+			name:   "diagonal to cactus below",
+			layers: []string{"##\n#C", "..\n.."},
+			pos:    cube.Pos{0, 1, 0},
+			want:   path.DANGER_OTHER,
+		},
+		{
+			name:   "diagonal to lava below",
+			layers: []string{"##\n#L", "..\n.."},
+			pos:    cube.Pos{0, 1, 0},
+			want:   path.DANGER_FIRE,
+		},
+		{
+			name:   "diagonal to fire above",
+			layers: []string{"##\n##", "..\n..", "..\n.f"},
+			pos:    cube.Pos{0, 1, 0},
+			want:   path.DANGER_FIRE,
+		},
+		{
+			name:   "diagonal to water below",
+			layers: []string{"##\n#~", "..\n.."},
+			pos:    cube.Pos{0, 1, 0},
+			want:   path.WATER_BORDER,
+		},
+		{
+			name:   "solid block",
+			layers: []string{"#"},
+			pos:    cube.Pos{0, 0, 0},
+			want:   path.BLOCKED,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := testworld.Parse(legend, tt.layers...)
+			if got := evaluator.BlockPathType(w, tt.pos); got != tt.want {
+				t.Errorf("BlockPathType() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package evaluator
+
+import (
+	"github.com/FDUTCH/Pathfinder"
+	"github.com/FDUTCH/Pathfinder/path"
+	"github.com/df-mc/dragonfly/server/block"
+	"github.com/df-mc/dragonfly/server/block/cube"
+	"github.com/df-mc/dragonfly/server/world"
+	"github.com/go-gl/mathgl/mgl64"
+	"slices"
+)
+
+type FlyNodeEvaluatorConfig struct {
+	CostMap      path.CostMap
+	Box          cube.BBox
+	Pos          mgl64.Vec3
+	CanPathDoors bool
+	CanOpenDoors bool
+	CanFloat     bool
+	// MinAltitude and MaxAltitude are the bounds of the preferred height above the ground. The band is
+	// disabled if MaxAltitude is 0.
+	MinAltitude, MaxAltitude int
+	// AltitudeMalus is the cost added for each block a node is outside the preferred altitude band.
+	AltitudeMalus float64
+	// Cache is an optional PathTypeCache shared with other evaluators.
+	Cache *PathTypeCache
+}
+
+func (c FlyNodeEvaluatorConfig) New() *FlyNodeEvaluator {
+	if c.MaxAltitude > 0 && c.AltitudeMalus == 0 {
+		c.AltitudeMalus = 1
+	}
+	if c.MinAltitude > c.MaxAltitude {
+		c.MinAltitude, c.MaxAltitude = c.MaxAltitude, c.MinAltitude
+	}
+
+	e := &FlyNodeEvaluator{
+		nodeEvaluator: newNodeEvaluator(c.CostMap, c.Box, c.Pos, c.CanPathDoors, c.CanOpenDoors, c.CanFloat),
+		minAltitude:   c.MinAltitude,
+		maxAltitude:   c.MaxAltitude,
+		altitudeMalus: c.AltitudeMalus,
+	}
+	e.cache, e.kind = c.Cache, kindFly
+	return e
+}
+
+// FlyNodeEvaluator implements pathfind.NodeEvaluator for entities that fly. Unlike WalkNodeEvaluator it
+// does not require a floor below nodes and expands in all 26 directions.
+type FlyNodeEvaluator struct {
+	nodeEvaluator
+
+	minAltitude, maxAltitude int
+	altitudeMalus            float64
+}
+
+func (e *FlyNodeEvaluator) StartNode() *pathfind.Node {
+	pos := e.startPosition
+	if water, isWater := e.source.Block(pos).(block.Water); isWater && e.canFloat {
+		for isWater && isSourceWaterBlock(water) {
+			pos[1]++
+			water, isWater = e.source.Block(pos).(block.Water)
+		}
+	}
+	node := e.Node(pos)
+	node.Type = e.CachedBlockPathType(e.source, pos)
+	node.CostMalus = e.pathTypeCostMap.PathfindingMalus(node.Type)
+	return node
+}
+
+func (e *FlyNodeEvaluator) Neighbors(node *pathfind.Node) []*pathfind.Node {
+	var (
+		nodes = e.neighbors[:0]
+		open  [3][3][3]bool
+	)
+
+	// Neighbours are visited sorted by the amount of axes they move on, so that diagonal moves can check
+	// if the moves they are made of are open.
+	for axes := 1; axes <= 3; axes++ {
+		for x := -1; x <= 1; x++ {
+			for y := -1; y <= 1; y++ {
+				for z := -1; z <= 1; z++ {
+					if pathfind.Abs(x)+pathfind.Abs(y)+pathfind.Abs(z) != axes || !e.componentsOpen(&open, x, y, z) {
+						continue
+					}
+					neighbor := e.acceptedNode(node.Add(cube.Pos{x, y, z}))
+					if neighbor == nil || neighbor.CostMalus < 0 {
+						continue
+					}
+					open[x+1][y+1][z+1] = true
+					if !neighbor.Closed {
+						nodes = append(nodes, neighbor)
+					}
+				}
+			}
+		}
+	}
+	e.neighbors = nodes
+	return nodes
+}
+
+// componentsOpen checks if all moves along a subset of the axes of the move passed are open.
+func (e *FlyNodeEvaluator) componentsOpen(open *[3][3][3]bool, x, y, z int) bool {
+	for _, c := range [][3]int{{x, 0, 0}, {0, y, 0}, {0, 0, z}, {x, y, 0}, {x, 0, z}, {0, y, z}} {
+		if c == [3]int{x, y, z} || c == [3]int{} {
+			continue
+		}
+		if !open[c[0]+1][c[1]+1][c[2]+1] {
+			return false
+		}
+	}
+	return true
+}
+
+// acceptedNode returns node from position, or nil if the entity cannot fly through it.
+func (e *FlyNodeEvaluator) acceptedNode(pos cube.Pos) *pathfind.Node {
+	pathType := e.CachedBlockPathType(e.source, pos)
+	malus := e.pathTypeCostMap.PathfindingMalus(pathType)
+	if malus < 0 {
+		return nil
+	}
+	if pathType == path.WALKABLE {
+		malus++
+	}
+	return e.nodeAndUpdateCostToMax(pos, pathType, malus+e.altitudeCost(pos))
+}
+
+// altitudeCost returns the malus for flying at pos according to the preferred altitude band.
+func (e *FlyNodeEvaluator) altitudeCost(pos cube.Pos) float64 {
+	if e.maxAltitude <= 0 {
+		return 0
+	}
+	altitude := e.altitude(pos)
+	switch {
+	case altitude < e.minAltitude:
+		return float64(e.minAltitude-altitude) * e.altitudeMalus
+	case altitude > e.maxAltitude:
+		return float64(altitude-e.maxAltitude) * e.altitudeMalus
+	}
+	return 0
+}
+
+// altitude returns the height of pos above the ground. Heights above the preferred band are only checked
+// up to one block past the band.
+func (e *FlyNodeEvaluator) altitude(pos cube.Pos) int {
+	for altitude := 0; altitude <= e.maxAltitude; altitude++ {
+		below := pos.Sub(cube.Pos{0, altitude + 1, 0})
+		if below.Y() < -64 || !pathfind.ComputationTypeAir.Pathfindable(e.source.Block(below), e.source, below) {
+			return altitude
+		}
+	}
+	return e.maxAltitude + 1
+}
+
+// BlockPathTypes returns path.BlockPathType at pos and all path types in the space occupied by the entity.
+func (e *FlyNodeEvaluator) BlockPathTypes(source world.BlockSource, pos cube.Pos, pathType path.BlockPathType, mobPos cube.Pos) (path.BlockPathType, []path.BlockPathType) {
+	return e.blockPathTypes(source, pos, pathType, mobPos, FlyBlockPathType)
+}
+
+// ValidNode checks if a node of an existing path can still be passed in the source passed.
+func (e *FlyNodeEvaluator) ValidNode(source world.BlockSource, node *pathfind.Node) bool {
+	return e.validPathType(node, e.blockPathTypeAt(source, node.Pos))
+}
+
+// CachedBlockPathType returns cached path.BlockPathType from position.
+func (e *FlyNodeEvaluator) CachedBlockPathType(source world.BlockSource, pos cube.Pos) path.BlockPathType {
+	return e.cachedBlockPathType(source, pos, e.blockPathTypeAt)
+}
+
+// blockPathTypeAt returns path.BlockPathType for passed position.
+func (e *FlyNodeEvaluator) blockPathTypeAt(source world.BlockSource, pos cube.Pos) path.BlockPathType {
+	currentPathType, pathTypes := e.BlockPathTypes(source, pos, path.BLOCKED, e.startPosition)
+	if slices.Contains(pathTypes, path.FENCE) {
+		return path.FENCE
+	}
+	bestPathType := path.BLOCKED
+
+	for _, pathType := range pathTypes {
+		cost := e.pathTypeCostMap.PathfindingMalus(pathType)
+		if cost < 0 {
+			return pathType
+		}
+		if cost >= e.pathTypeCostMap.PathfindingMalus(bestPathType) {
+			bestPathType = pathType
+		}
+	}
+
+	if currentPathType == path.OPEN && e.pathTypeCostMap.PathfindingMalus(bestPathType) == 0 {
+		return path.OPEN
+	}
+	return bestPathType
+}
+
+// FlyBlockPathType returns path.BlockPathType for passed position for flying entities.
+func FlyBlockPathType(source world.BlockSource, pos cube.Pos) path.BlockPathType {
+	pathType := BlockPathTypeRaw(source, pos)
+	if pathType == path.OPEN && pos[1] >= (-64+1) {
+		pathTypeDown := BlockPathTypeRaw(source, pos.Side(cube.FaceDown))
+
+		switch pathTypeDown {
+		case path.DAMAGE_FIRE, path.LAVA:
+			pathType = path.DAMAGE_FIRE
+		case path.DAMAGE_OTHER:
+			pathType = path.DAMAGE_OTHER
+		case path.COCOA:
+			pathType = path.COCOA
+		case path.FENCE:
+			pathType = path.FENCE
+		case path.WALKABLE, path.OPEN, path.WATER:
+			pathType = path.OPEN
+		default:
+			pathType = path.WALKABLE
+		}
+	}
+
+	if pathType == path.WALKABLE || pathType == path.OPEN {
+		pathType = CheckNeighbourBlocks(source, pos, pathType)
+	}
+	return pathType
+}
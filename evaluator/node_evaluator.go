@@ -0,0 +1,339 @@
+package evaluator
+
+import (
+	"github.com/FDUTCH/Pathfinder"
+	"github.com/FDUTCH/Pathfinder/path"
+	"github.com/df-mc/dragonfly/server/block"
+	"github.com/df-mc/dragonfly/server/block/cube"
+	"github.com/df-mc/dragonfly/server/world"
+	"github.com/go-gl/mathgl/mgl64"
+	"golang.org/x/exp/maps"
+	"math"
+	"slices"
+)
+
+// nodeEvaluator holds the state shared by the node evaluators of this package.
+type nodeEvaluator struct {
+	pathTypeCostMap path.CostMap
+	source          world.BlockSource
+
+	startPosition cube.Pos
+	nodes         map[cube.Pos]*pathfind.Node
+	arena         pathfind.NodeArena
+	targets       []*pathfind.Target
+	usedTargets   int
+	// neighbors is the buffer returned by Neighbors, reused by each call.
+	neighbors []*pathfind.Node
+
+	entitySizeInfo EntitySizeInfo
+	boundingBox    cube.BBox
+
+	canPassDoors, canOpenDoors, canBreakDoors, canOpenGates, canFloat, canClimb bool
+
+	pathTypesByPosCache map[cube.Pos]path.BlockPathType
+	cache               *PathTypeCache
+	kind                evaluatorKind
+	// scaleMalus, if not nil, returns the cost malus of the node at the position passed from the malus of its
+	// path type in the cost map.
+	scaleMalus func(pos cube.Pos, malus float64) float64
+	// scope is the cacheScope of the current search.
+	scope cacheScope
+}
+
+// newNodeEvaluator ...
+func newNodeEvaluator(costMap path.CostMap, box cube.BBox, pos mgl64.Vec3, canPassDoors, canOpenDoors, canFloat bool) nodeEvaluator {
+	if costMap == nil {
+		costMap = map[path.BlockPathType]float64{}
+	}
+	return nodeEvaluator{
+		pathTypeCostMap:     costMap,
+		startPosition:       cube.PosFromVec3(pos),
+		entitySizeInfo:      EntitySizeInfo{box},
+		boundingBox:         box.Translate(pos),
+		canPassDoors:        canPassDoors,
+		canOpenDoors:        canOpenDoors,
+		canFloat:            canFloat,
+		nodes:               map[cube.Pos]*pathfind.Node{},
+		pathTypesByPosCache: map[cube.Pos]path.BlockPathType{},
+	}
+}
+
+func (e *nodeEvaluator) CanPassDoors() bool {
+	return e.canPassDoors
+}
+
+func (e *nodeEvaluator) SetCanPassDoors(canPassDoors bool) {
+	e.canPassDoors = canPassDoors
+}
+
+func (e *nodeEvaluator) CanOpenDoors() bool {
+	return e.canOpenDoors
+}
+
+func (e *nodeEvaluator) SetCanOpenDoors(canOpenDoors bool) {
+	e.canOpenDoors = canOpenDoors
+}
+
+// CanBreakDoors reports if the entity may path through closed wooden doors by breaking them.
+func (e *nodeEvaluator) CanBreakDoors() bool {
+	return e.canBreakDoors
+}
+
+// SetCanBreakDoors sets if the entity may path through closed wooden doors by breaking them.
+func (e *nodeEvaluator) SetCanBreakDoors(canBreakDoors bool) {
+	e.canBreakDoors = canBreakDoors
+}
+
+// CanOpenGates reports if the entity may path through closed fence gates by opening them.
+func (e *nodeEvaluator) CanOpenGates() bool {
+	return e.canOpenGates
+}
+
+// SetCanOpenGates sets if the entity may path through closed fence gates by opening them.
+func (e *nodeEvaluator) SetCanOpenGates(canOpenGates bool) {
+	e.canOpenGates = canOpenGates
+}
+
+func (e *nodeEvaluator) CanFloat() bool {
+	return e.canFloat
+}
+
+func (e *nodeEvaluator) SetCanFloat(canFloat bool) {
+	e.canFloat = canFloat
+}
+
+func (e *nodeEvaluator) CanClimb() bool {
+	return e.canClimb
+}
+
+func (e *nodeEvaluator) SetCanClimb(canClimb bool) {
+	e.canClimb = canClimb
+}
+
+// TargetFromNode returns a target at the node. Targets are reused by the next search of the evaluator.
+func (e *nodeEvaluator) TargetFromNode(node *pathfind.Node) *pathfind.Target {
+	if e.usedTargets == len(e.targets) {
+		e.targets = append(e.targets, &pathfind.Target{})
+	}
+	target := e.targets[e.usedTargets]
+	target.Reset(node)
+	e.usedTargets++
+	return target
+}
+
+// Prepare prepares the evaluator for a search, reusing the nodes and targets of the previous search.
+func (e *nodeEvaluator) Prepare(source world.BlockSource, pos cube.Pos) {
+	e.source = source
+	e.startPosition = pos
+	clear(e.nodes)
+	e.arena.Reset()
+	e.usedTargets = 0
+	if e.cache != nil {
+		e.scope = e.cacheScope()
+	}
+}
+
+// UpdateSource replaces the world.BlockSource used by a prepared evaluator.
+func (e *nodeEvaluator) UpdateSource(source world.BlockSource) {
+	e.source = source
+}
+
+func (e *nodeEvaluator) Done() {
+	maps.Clear(e.pathTypesByPosCache)
+	e.source = nil
+}
+
+func (e *nodeEvaluator) Node(pos cube.Pos) *pathfind.Node {
+	node, has := e.nodes[pos]
+	if !has {
+		node = e.arena.New(pos)
+		e.nodes[pos] = node
+		return node
+	}
+	return node
+}
+
+func (e *nodeEvaluator) Goal(pos cube.Pos) *pathfind.Target {
+	return e.TargetFromNode(e.Node(pos))
+}
+
+// cachedBlockPathType returns the path.BlockPathType at pos from the caches of the evaluator, computing it
+// with the function passed if it is not cached yet.
+func (e *nodeEvaluator) cachedBlockPathType(source world.BlockSource, pos cube.Pos, compute func(world.BlockSource, cube.Pos) path.BlockPathType) path.BlockPathType {
+	t, has := e.pathTypesByPosCache[pos]
+	if has {
+		return t
+	}
+	if e.cache != nil {
+		if t, has = e.cache.load(pos, e.scope); has {
+			e.pathTypesByPosCache[pos] = t
+			return t
+		}
+	}
+	t = compute(source, pos)
+	e.pathTypesByPosCache[pos] = t
+	if e.cache != nil {
+		e.cache.store(pos, e.scope, t)
+	}
+	return t
+}
+
+// blockedNode returns new blocked pathfind.Node. The node is not shared with the rest of the search, so that
+// a node that was already accepted at the same position is not marked as blocked.
+func (e *nodeEvaluator) blockedNode(pos cube.Pos) *pathfind.Node {
+	node := e.arena.New(pos)
+	node.Type = path.BLOCKED
+	node.CostMalus = -1
+
+	return node
+}
+
+// canReachWithoutCollision ...
+func (e *nodeEvaluator) canReachWithoutCollision(node *pathfind.Node) (result bool) {
+	bb := e.boundingBox
+	mobPos := e.startPosition
+
+	relativePos := mgl64.Vec3{
+		float64(node.X()-mobPos.X()) + bb.Width()/2,
+		float64(node.Y()-mobPos.Y()) + bb.Height()/2,
+		float64(node.Z()-mobPos.Z()) + bb.Length()/2,
+	}
+	return canMoveWithoutCollision(e.source, bb, relativePos, pathfind.AverageEdgeLength(bb))
+}
+
+// hasCollisions ...
+func (e *nodeEvaluator) hasCollisions(bb cube.BBox) (result bool) {
+	return hasCollisions(e.source, bb)
+}
+
+// canMoveWithoutCollision checks if bb can be moved by relativePos without colliding with blocks, checking
+// every stepLength blocks.
+func canMoveWithoutCollision(source world.BlockSource, bb cube.BBox, relativePos mgl64.Vec3, stepLength float64) bool {
+	stepCount := int(math.Ceil(relativePos.Len() / stepLength))
+	relativePos = relativePos.Mul(1 / float64(stepCount))
+
+	for i := 1; i <= stepCount; i++ {
+		bb = bb.Translate(relativePos)
+		if hasCollisions(source, bb) {
+			return false
+		}
+	}
+	return true
+}
+
+// hasCollisions checks if bb intersects with the collision boxes of blocks.
+func hasCollisions(source world.BlockSource, bb cube.BBox) (result bool) {
+	Max := cube.PosFromVec3(bb.Max()).Add(cube.Pos{1, 1, 1})
+	Min := cube.PosFromVec3(bb.Min()).Sub(cube.Pos{1, 1, 1})
+	for z := Min.Z(); z <= Max.Z(); z++ {
+		for x := Min.X(); x <= Max.X(); x++ {
+			for y := Min.Y(); y <= Max.Y(); y++ {
+				pos := cube.Pos{x, y, z}
+				bl := source.Block(pos)
+				for _, box := range bl.Model().BBox(pos, source) {
+					if box.Translate(pos.Vec3()).IntersectsWith(bb) {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
+// validPathType checks if a node of a path can still be passed now that its position has the path type passed.
+// The node stays valid if its path type did not change, or if it changed to a path type that is not open and
+// not more costly.
+func (e *nodeEvaluator) validPathType(node *pathfind.Node, pathType path.BlockPathType) bool {
+	if pathType == node.Type {
+		return true
+	}
+	malus := e.pathTypeCostMap.PathfindingMalus(pathType)
+	return malus >= 0 && malus <= node.CostMalus && pathType != path.OPEN
+}
+
+// nodeAndUpdateCostToMax ...
+func (e *nodeEvaluator) nodeAndUpdateCostToMax(pos cube.Pos, pathType path.BlockPathType, malus float64) *pathfind.Node {
+	if malus >= 0 && e.scaleMalus != nil {
+		malus = e.scaleMalus(pos, malus)
+	}
+	node := e.Node(pos)
+	node.Type = pathType
+	node.CostMalus = max(node.CostMalus, malus)
+	switch pathType {
+	case path.WALKABLE_DOOR:
+		node.Action = e.doorAction()
+	case path.WALKABLE_GATE:
+		node.Action = pathfind.ActionOpenGate
+	}
+	return node
+}
+
+// doorAction returns the action the entity takes to pass a closed wooden door. Doors are opened rather than
+// broken if the entity can do both.
+func (e *nodeEvaluator) doorAction() pathfind.NodeAction {
+	if e.canOpenDoors {
+		return pathfind.ActionOpenDoor
+	}
+	return pathfind.ActionBreakDoor
+}
+
+// evaluateBlockPathType returns the path.BlockPathType of the block at pos for the capabilities of the entity.
+func (e *nodeEvaluator) evaluateBlockPathType(source world.BlockSource, pos cube.Pos, pathType path.BlockPathType) path.BlockPathType {
+	if pathType == path.DOOR_WOOD_CLOSED && e.canPassDoors && (e.canOpenDoors || e.canBreakDoors) {
+		pathType = path.WALKABLE_DOOR
+	} else if pathType == path.DOOR_OPEN && !e.canPassDoors {
+		pathType = path.BLOCKED
+	} else if pathType == path.FENCE_GATE_CLOSED {
+		pathType = path.FENCE
+		if e.canOpenGates {
+			pathType = path.WALKABLE_GATE
+		}
+	} else if pathType == path.CLIMBABLE && !e.canClimb {
+		// Entities that cannot climb walk through climbable blocks like through any other block without
+		// collision, so they may stand in them.
+		pathType = openBlockPathType(source, pos, path.OPEN)
+	}
+	//else if pathType == path_type.RAIL {
+	//
+	//}
+	return pathType
+}
+
+// isEntityUnderwater ...
+func (e *nodeEvaluator) isEntityUnderwater() bool {
+	start := e.startPosition
+	start[1] += e.entitySizeInfo.heightInt()
+	bl, water := e.source.Block(start).(block.Water)
+	if !water {
+		return false
+	}
+
+	f := float64(start[1]+1) - (waterDepthPercent(bl) - 0.1111111)
+
+	return (float64(e.startPosition[1]) + e.entitySizeInfo.Height()) < f
+}
+
+// blockPathTypes returns path.BlockPathType at pos and all path types in the space occupied by the entity,
+// classifying each block using the function passed.
+func (e *nodeEvaluator) blockPathTypes(source world.BlockSource, pos cube.Pos, pathType path.BlockPathType, mobPos cube.Pos, classify func(world.BlockSource, cube.Pos) path.BlockPathType) (path.BlockPathType, []path.BlockPathType) {
+	var pathTypes []path.BlockPathType
+
+	var entityWidth, entityHeight, entityDepth = e.entitySizeInfo.widthInt(), e.entitySizeInfo.heightInt(), e.entitySizeInfo.depthInt()
+	for currentX := 0; currentX < entityWidth; currentX++ {
+		for currentY := 0; currentY < entityHeight; currentY++ {
+			for currentZ := 0; currentZ < entityDepth; currentZ++ {
+				currentPos := pos.Add(cube.Pos{currentX, currentY, currentZ})
+				currentPathType := e.evaluateBlockPathType(source, currentPos, classify(source, currentPos))
+				if currentX == 0 && currentY == 0 && currentZ == 0 {
+					pathType = currentPathType
+				}
+				if !slices.Contains(pathTypes, currentPathType) {
+					pathTypes = append(pathTypes, currentPathType)
+				}
+			}
+		}
+	}
+	slices.Sort(pathTypes)
+	return pathType, pathTypes
+}
@@ -0,0 +1,157 @@
+package evaluator
+
+import (
+	"encoding/binary"
+	"github.com/FDUTCH/Pathfinder/path"
+	"github.com/df-mc/dragonfly/server/block/cube"
+	"github.com/df-mc/dragonfly/server/world"
+	"hash/fnv"
+	"math"
+	"sync"
+	"sync/atomic"
+)
+
+// invalidationMargin is the distance from a changed block within which cached path types may depend on it.
+const invalidationMargin = 3
+
+// evaluatorKind identifies the way an evaluator classifies blocks, so that evaluators of different kinds
+// sharing a PathTypeCache do not read each other's entries.
+type evaluatorKind byte
+
+const (
+	kindWalk evaluatorKind = iota
+	kindFly
+	kindSwim
+	kindAmphibious
+)
+
+// PathTypeCache is a path.BlockPathType cache that may be shared by evaluators and persists across searches.
+// Entries are grouped by chunk and kept apart for each kind of evaluator, EntitySizeInfo, cost map and set of
+// capabilities, so that only evaluators that classify blocks the same way share them. Changes to the cost map
+// or capabilities of an evaluator are picked up by its next search. A PathTypeCache is safe for concurrent use.
+type PathTypeCache struct {
+	mu     sync.RWMutex
+	chunks map[world.ChunkPos]map[cacheKey]path.BlockPathType
+
+	hits, misses atomic.Uint64
+}
+
+// cacheKey is the key of an entry in a chunk of a PathTypeCache.
+type cacheKey struct {
+	pos cube.Pos
+	cacheScope
+}
+
+// cacheScope holds the properties of an evaluator that the path types it classifies depend on.
+type cacheScope struct {
+	size EntitySizeInfo
+	kind evaluatorKind
+	// capabilities is a hash of the cost map and capabilities of the evaluator.
+	capabilities uint64
+}
+
+// CacheStats holds statistics of a PathTypeCache.
+type CacheStats struct {
+	// Hits and Misses are the amount of lookups that did and did not find an entry.
+	Hits, Misses uint64
+	// Chunks is the amount of chunks with entries.
+	Chunks int
+	// Entries is the amount of entries.
+	Entries int
+}
+
+// NewPathTypeCache returns an empty PathTypeCache.
+func NewPathTypeCache() *PathTypeCache {
+	return &PathTypeCache{chunks: map[world.ChunkPos]map[cacheKey]path.BlockPathType{}}
+}
+
+// load returns the cached path.BlockPathType at pos.
+func (c *PathTypeCache) load(pos cube.Pos, scope cacheScope) (path.BlockPathType, bool) {
+	c.mu.RLock()
+	t, ok := c.chunks[chunkPosOf(pos)][cacheKey{pos: pos, cacheScope: scope}]
+	c.mu.RUnlock()
+
+	if ok {
+		c.hits.Add(1)
+	} else {
+		c.misses.Add(1)
+	}
+	return t, ok
+}
+
+// store caches the path.BlockPathType at pos.
+func (c *PathTypeCache) store(pos cube.Pos, scope cacheScope, pathType path.BlockPathType) {
+	chunkPos := chunkPosOf(pos)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	chunk, ok := c.chunks[chunkPos]
+	if !ok {
+		chunk = map[cacheKey]path.BlockPathType{}
+		c.chunks[chunkPos] = chunk
+	}
+	chunk[cacheKey{pos: pos, cacheScope: scope}] = pathType
+}
+
+// Invalidate removes the entries that may depend on the block at pos. It should be called when the block
+// at pos changes.
+func (c *PathTypeCache) Invalidate(pos cube.Pos) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, x := range []int{-invalidationMargin, 0, invalidationMargin} {
+		for _, z := range []int{-invalidationMargin, 0, invalidationMargin} {
+			delete(c.chunks, chunkPosOf(pos.Add(cube.Pos{x, 0, z})))
+		}
+	}
+}
+
+// InvalidateChunk removes all entries in the chunk passed.
+func (c *PathTypeCache) InvalidateChunk(chunkPos world.ChunkPos) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.chunks, chunkPos)
+}
+
+// Clear removes all entries and resets the statistics.
+func (c *PathTypeCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.chunks)
+	c.hits.Store(0)
+	c.misses.Store(0)
+}
+
+// Stats returns statistics of the cache.
+func (c *PathTypeCache) Stats() CacheStats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	stats := CacheStats{Hits: c.hits.Load(), Misses: c.misses.Load(), Chunks: len(c.chunks)}
+	for _, chunk := range c.chunks {
+		stats.Entries += len(chunk)
+	}
+	return stats
+}
+
+// cacheScope returns the cacheScope of the evaluator.
+func (e *nodeEvaluator) cacheScope() cacheScope {
+	h := fnv.New64a()
+	var buf [9]byte
+	for _, pathType := range path.Types() {
+		buf[0] = byte(pathType)
+		binary.LittleEndian.PutUint64(buf[1:], math.Float64bits(e.pathTypeCostMap.PathfindingMalus(pathType)))
+		_, _ = h.Write(buf[:])
+	}
+	var flags byte
+	for i, flag := range []bool{e.canPassDoors, e.canOpenDoors, e.canBreakDoors, e.canOpenGates, e.canFloat, e.canClimb} {
+		if flag {
+			flags |= 1 << i
+		}
+	}
+	_, _ = h.Write([]byte{flags})
+	return cacheScope{size: e.entitySizeInfo, kind: e.kind, capabilities: h.Sum64()}
+}
+
+// chunkPosOf returns the position of the chunk containing pos.
+func chunkPosOf(pos cube.Pos) world.ChunkPos {
+	return world.ChunkPos{int32(pos.X() >> 4), int32(pos.Z() >> 4)}
+}
@@ -0,0 +1,76 @@
+package evaluator_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	pathfind "github.com/FDUTCH/Pathfinder"
+	"github.com/FDUTCH/Pathfinder/evaluator"
+	"github.com/FDUTCH/Pathfinder/internal/testworld"
+	"github.com/FDUTCH/Pathfinder/path"
+	"github.com/df-mc/dragonfly/server/block"
+	"github.com/df-mc/dragonfly/server/block/cube"
+)
+
+// cachedSearch searches a path through the door at x=3 using an evaluator made from the config passed.
+func cachedSearch(w *testworld.World, config evaluator.WalkNodeEvaluatorConfig) error {
+	config.Box = playerBox
+	_, err := pathfind.FindPathContext(context.Background(), config.New(), w, cube.Pos{1, 1, 2}, cube.Pos{4, 1, 2}, 1000, 32, 0)
+	return err
+}
+
+func TestPathTypeCacheSharing(t *testing.T) {
+	w := testworld.New(flatFloor, doorway("D"), doorway("D"))
+	cache := evaluator.NewPathTypeCache()
+
+	if err := cachedSearch(w, evaluator.WalkNodeEvaluatorConfig{Cache: cache}); !errors.Is(err, pathfind.ErrUnreachable) {
+		t.Fatalf("search without opening doors error = %v, want %v", err, pathfind.ErrUnreachable)
+	}
+	entries := cache.Stats().Entries
+
+	// An evaluator with the same cost map and capabilities only reads the entries stored.
+	if err := cachedSearch(w, evaluator.WalkNodeEvaluatorConfig{Cache: cache}); !errors.Is(err, pathfind.ErrUnreachable) {
+		t.Fatalf("second search without opening doors error = %v, want %v", err, pathfind.ErrUnreachable)
+	}
+	if stats := cache.Stats(); stats.Entries != entries || stats.Hits == 0 {
+		t.Errorf("Stats() after same search = %+v, want %v entries and hits", stats, entries)
+	}
+
+	// Evaluators that classify blocks differently do not read those entries.
+	if err := cachedSearch(w, evaluator.WalkNodeEvaluatorConfig{Cache: cache, CanPathDoors: true, CanOpenDoors: true}); err != nil {
+		t.Errorf("search opening doors error = %v, want nil", err)
+	}
+	costMap := path.CostMap{path.WALKABLE_DOOR: path.BLOCKED_MALUS}
+	if err := cachedSearch(w, evaluator.WalkNodeEvaluatorConfig{Cache: cache, CanPathDoors: true, CanOpenDoors: true, CostMap: costMap}); !errors.Is(err, pathfind.ErrUnreachable) {
+		t.Errorf("search opening doors with blocked doors error = %v, want %v", err, pathfind.ErrUnreachable)
+	}
+	if err := cachedSearch(w, evaluator.WalkNodeEvaluatorConfig{Cache: cache}); !errors.Is(err, pathfind.ErrUnreachable) {
+		t.Errorf("search without opening doors after opening them error = %v, want %v", err, pathfind.ErrUnreachable)
+	}
+}
+
+func TestPathTypeCacheInvalidate(t *testing.T) {
+	w := testworld.New(flatFloor, doorway("#"), doorway("#"))
+	cache := evaluator.NewPathTypeCache()
+	if err := cachedSearch(w, evaluator.WalkNodeEvaluatorConfig{Cache: cache}); !errors.Is(err, pathfind.ErrUnreachable) {
+		t.Fatalf("search through wall error = %v, want %v", err, pathfind.ErrUnreachable)
+	}
+
+	entries := cache.Stats().Entries
+	for _, pos := range []cube.Pos{{3, 1, 2}, {3, 2, 2}} {
+		w.Set(pos, block.Air{})
+		cache.Invalidate(pos)
+	}
+	if stats := cache.Stats(); stats.Entries >= entries {
+		t.Errorf("Stats() after Invalidate() = %+v, want less than %v entries", stats, entries)
+	}
+	if err := cachedSearch(w, evaluator.WalkNodeEvaluatorConfig{Cache: cache}); err != nil {
+		t.Errorf("search through gap error = %v, want nil", err)
+	}
+
+	cache.Clear()
+	if stats := cache.Stats(); stats != (evaluator.CacheStats{}) {
+		t.Errorf("Stats() after Clear() = %+v, want zero", stats)
+	}
+}
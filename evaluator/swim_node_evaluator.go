@@ -0,0 +1,158 @@
+package evaluator
+
+import (
+	"github.com/FDUTCH/Pathfinder"
+	"github.com/FDUTCH/Pathfinder/path"
+	"github.com/df-mc/dragonfly/server/block"
+	"github.com/df-mc/dragonfly/server/block/cube"
+	"github.com/df-mc/dragonfly/server/world"
+	"github.com/go-gl/mathgl/mgl64"
+	"golang.org/x/exp/maps"
+)
+
+type SwimNodeEvaluatorConfig struct {
+	CostMap path.CostMap
+	Box     cube.BBox
+	Pos     mgl64.Vec3
+	// AllowBreaching allows paths to leave the water through the surface.
+	AllowBreaching bool
+	// LeaveWaterMalus is the cost added to nodes that are outside the water. Defaults to 8.
+	LeaveWaterMalus float64
+	// Cache is an optional PathTypeCache shared with other evaluators.
+	Cache *PathTypeCache
+}
+
+func (c SwimNodeEvaluatorConfig) New() *SwimNodeEvaluator {
+	costMap := maps.Clone(c.CostMap)
+	if costMap == nil {
+		costMap = path.CostMap{}
+	}
+	if _, has := costMap[path.WATER]; !has {
+		costMap.SetPathfindingMalus(path.WATER, 0)
+	}
+
+	if c.LeaveWaterMalus == 0 {
+		c.LeaveWaterMalus = 8
+	}
+
+	e := &SwimNodeEvaluator{
+		nodeEvaluator:   newNodeEvaluator(costMap, c.Box, c.Pos, false, false, false),
+		allowBreaching:  c.AllowBreaching,
+		leaveWaterMalus: c.LeaveWaterMalus,
+	}
+	e.cache, e.kind = c.Cache, kindSwim
+	return e
+}
+
+// SwimNodeEvaluator implements pathfind.NodeEvaluator for entities that swim through water in all
+// directions.
+type SwimNodeEvaluator struct {
+	nodeEvaluator
+
+	allowBreaching  bool
+	leaveWaterMalus float64
+}
+
+func (e *SwimNodeEvaluator) AllowBreaching() bool {
+	return e.allowBreaching
+}
+
+func (e *SwimNodeEvaluator) SetAllowBreaching(allowBreaching bool) {
+	e.allowBreaching = allowBreaching
+}
+
+func (e *SwimNodeEvaluator) StartNode() *pathfind.Node {
+	node := e.Node(e.startPosition)
+	node.Type = e.CachedBlockPathType(e.source, node.Pos)
+	node.CostMalus = e.pathTypeCostMap.PathfindingMalus(node.Type)
+	return node
+}
+
+func (e *SwimNodeEvaluator) Neighbors(node *pathfind.Node) []*pathfind.Node {
+	var (
+		nodes               = e.neighbors[:0]
+		horizontalNeighbors [6]*pathfind.Node
+	)
+
+	for _, face := range cube.Faces() {
+		neighbor := e.acceptedNode(node.Side(face))
+		horizontalNeighbors[face] = neighbor
+		if neighbor != nil && !neighbor.Closed {
+			nodes = append(nodes, neighbor)
+		}
+	}
+
+	for _, zFace := range []cube.Face{cube.FaceNorth, cube.FaceSouth} {
+		for _, xFace := range []cube.Face{cube.FaceEast, cube.FaceWest} {
+			if !e.hasMalus(horizontalNeighbors[zFace]) || !e.hasMalus(horizontalNeighbors[xFace]) {
+				continue
+			}
+			diagonal := e.acceptedNode(node.Side(zFace).Side(xFace))
+			if diagonal != nil && !diagonal.Closed {
+				nodes = append(nodes, diagonal)
+			}
+		}
+	}
+	e.neighbors = nodes
+	return nodes
+}
+
+// hasMalus checks if the node can be passed through.
+func (e *SwimNodeEvaluator) hasMalus(node *pathfind.Node) bool {
+	return node != nil && node.CostMalus >= 0
+}
+
+// acceptedNode returns node from position, or nil if the entity cannot swim through it.
+func (e *SwimNodeEvaluator) acceptedNode(pos cube.Pos) *pathfind.Node {
+	pathType := e.CachedBlockPathType(e.source, pos)
+	if pathType != path.WATER && (!e.allowBreaching || pathType != path.BREACH) {
+		return nil
+	}
+	malus := e.pathTypeCostMap.PathfindingMalus(pathType)
+	if malus < 0 {
+		return nil
+	}
+	if _, water := e.source.Block(pos).(block.Water); !water {
+		malus += e.leaveWaterMalus
+	}
+	return e.nodeAndUpdateCostToMax(pos, pathType, malus)
+}
+
+// ValidNode checks if a node of an existing path can still be passed in the source passed.
+func (e *SwimNodeEvaluator) ValidNode(source world.BlockSource, node *pathfind.Node) bool {
+	return e.validPathType(node, e.blockPathTypeAt(source, node.Pos))
+}
+
+// CachedBlockPathType returns cached path.BlockPathType from position.
+func (e *SwimNodeEvaluator) CachedBlockPathType(source world.BlockSource, pos cube.Pos) path.BlockPathType {
+	return e.cachedBlockPathType(source, pos, e.blockPathTypeAt)
+}
+
+// blockPathTypeAt returns path.BlockPathType for passed position taking the size of the entity into account.
+func (e *SwimNodeEvaluator) blockPathTypeAt(source world.BlockSource, pos cube.Pos) path.BlockPathType {
+	var entityWidth, entityHeight, entityDepth = e.entitySizeInfo.widthInt(), e.entitySizeInfo.heightInt(), e.entitySizeInfo.depthInt()
+	for currentX := 0; currentX < entityWidth; currentX++ {
+		for currentY := 0; currentY < entityHeight; currentY++ {
+			for currentZ := 0; currentZ < entityDepth; currentZ++ {
+				if pathType := SwimBlockPathType(source, pos.Add(cube.Pos{currentX, currentY, currentZ})); pathType != path.WATER {
+					return pathType
+				}
+			}
+		}
+	}
+	return path.WATER
+}
+
+// SwimBlockPathType returns path.BlockPathType for passed position for swimming entities. Air right above
+// water is path.BREACH.
+func SwimBlockPathType(source world.BlockSource, pos cube.Pos) path.BlockPathType {
+	switch source.Block(pos).(type) {
+	case block.Water:
+		return path.WATER
+	case block.Air:
+		if _, water := source.Block(pos.Side(cube.FaceDown)).(block.Water); water {
+			return path.BREACH
+		}
+	}
+	return path.BLOCKED
+}
@@ -9,9 +9,9 @@ import (
 	"github.com/df-mc/dragonfly/server/block/model"
 	"github.com/df-mc/dragonfly/server/world"
 	"github.com/go-gl/mathgl/mgl64"
-	"golang.org/x/exp/maps"
 	"math"
 	"slices"
+	"strings"
 )
 
 type WalkNodeEvaluatorConfig struct {
@@ -22,17 +22,24 @@ type WalkNodeEvaluatorConfig struct {
 	CanOpenDoors      bool
 	CanFloat          bool
 	CanWalkOverFences bool
+	CanClimb          bool
 	MaxStepUp         float64
 	MaxFallDistance   int
 	LiquidsCanStandOn []world.Liquid
+	// CanBreakDoors lets the entity path through closed wooden doors by breaking them, like zombies do on
+	// hard difficulty. CanPathDoors must be set too.
+	CanBreakDoors bool
+	// CanOpenGates lets the entity path through closed fence gates by opening them.
+	CanOpenGates bool
+	// AnyAngle makes searches connect nodes to any earlier node in a straight line, not just to the node
+	// they were reached from, so that paths are shorter and straighter.
+	AnyAngle bool
+	// Cache is an optional PathTypeCache shared with other evaluators.
+	Cache *PathTypeCache
 }
 
 func (c WalkNodeEvaluatorConfig) New() *WalkNodeEvaluator {
 
-	if c.CostMap == nil {
-		c.CostMap = map[path.BlockPathType]float64{}
-	}
-
 	if c.MaxStepUp == 0 {
 		c.MaxStepUp = 1
 	}
@@ -47,64 +54,35 @@ func (c WalkNodeEvaluatorConfig) New() *WalkNodeEvaluator {
 		liquids = append(liquids, world.BlockRuntimeID(l))
 	}
 
-	return &WalkNodeEvaluator{
-		pathTypeCostMap:       c.CostMap,
-		startPosition:         cube.PosFromVec3(c.Pos),
-		entitySizeInfo:        EntitySizeInfo{c.Box},
-		boundingBox:           c.Box.Translate(c.Pos),
-		canPassDoors:          c.CanPathDoors,
-		canOpenDoors:          c.CanOpenDoors,
-		canFloat:              c.CanFloat,
+	e := &WalkNodeEvaluator{
+		nodeEvaluator:         newNodeEvaluator(c.CostMap, c.Box, c.Pos, c.CanPathDoors, c.CanOpenDoors, c.CanFloat),
 		canWalkOverFences:     c.CanWalkOverFences,
+		classify:              BlockPathType,
 		maxUpStep:             c.MaxStepUp,
 		maxFallDistance:       c.MaxFallDistance,
 		liquidsThatCanStandOn: liquids,
-		pathTypesByPosCache:   map[cube.Pos]path.BlockPathType{},
 	}
+	e.canClimb = c.CanClimb
+	e.canBreakDoors = c.CanBreakDoors
+	e.canOpenGates = c.CanOpenGates
+	e.anyAngle = c.AnyAngle
+	e.cache = c.Cache
+	return e
 }
 
 // WalkNodeEvaluator implements pathfind.NodeEvaluator.
 type WalkNodeEvaluator struct {
-	pathTypeCostMap path.CostMap
-	source          world.BlockSource
+	nodeEvaluator
 
-	startPosition cube.Pos
-	nodes         map[cube.Pos]*pathfind.Node
+	canWalkOverFences bool
+	anyAngle          bool
 
-	entitySizeInfo EntitySizeInfo
-	boundingBox    cube.BBox
-
-	canPassDoors, canOpenDoors, canFloat, canWalkOverFences bool
+	// classify returns the path.BlockPathType of a single block.
+	classify func(world.BlockSource, cube.Pos) path.BlockPathType
 
 	maxUpStep             float64
 	maxFallDistance       int
 	liquidsThatCanStandOn []uint32
-
-	pathTypesByPosCache map[cube.Pos]path.BlockPathType
-}
-
-func (e *WalkNodeEvaluator) CanPassDoors() bool {
-	return e.canPassDoors
-}
-
-func (e *WalkNodeEvaluator) SetCanPassDoors(canPassDoors bool) {
-	e.canPassDoors = canPassDoors
-}
-
-func (e *WalkNodeEvaluator) CanOpenDoors() bool {
-	return e.canOpenDoors
-}
-
-func (e *WalkNodeEvaluator) SetCanOpenDoors(canOpenDoors bool) {
-	e.canOpenDoors = canOpenDoors
-}
-
-func (e *WalkNodeEvaluator) CanFloat() bool {
-	return e.canFloat
-}
-
-func (e *WalkNodeEvaluator) SetCanFloat(canFloat bool) {
-	e.canFloat = canFloat
 }
 
 func (e *WalkNodeEvaluator) CanWalkOverFences() bool {
@@ -115,20 +93,13 @@ func (e *WalkNodeEvaluator) SetCanWalkOverFences(canWalkOverFences bool) {
 	e.canWalkOverFences = canWalkOverFences
 }
 
-func (e *WalkNodeEvaluator) TargetFromNode(node *pathfind.Node) *pathfind.Target {
-	return pathfind.NewTarget(node)
+// AnyAngle reports if searches look for any-angle paths.
+func (e *WalkNodeEvaluator) AnyAngle() bool {
+	return e.anyAngle
 }
 
-func (e *WalkNodeEvaluator) Prepare(source world.BlockSource, pos cube.Pos) {
-	e.source = source
-	e.startPosition = pos
-	e.nodes = make(map[cube.Pos]*pathfind.Node)
-}
-
-func (e *WalkNodeEvaluator) Done() {
-	maps.Clear(e.pathTypesByPosCache)
-	e.nodes = nil
-	e.source = nil
+func (e *WalkNodeEvaluator) SetAnyAngle(anyAngle bool) {
+	e.anyAngle = anyAngle
 }
 
 func (e *WalkNodeEvaluator) StartNode() *pathfind.Node {
@@ -176,16 +147,6 @@ func (e *WalkNodeEvaluator) CanStandOnFluid(liquid world.Liquid) bool {
 	return slices.Contains(e.liquidsThatCanStandOn, world.BlockRuntimeID(liquid))
 }
 
-func (e *WalkNodeEvaluator) Node(pos cube.Pos) *pathfind.Node {
-	node, has := e.nodes[pos]
-	if !has {
-		node = pathfind.NewNode(pos)
-		e.nodes[pos] = node
-		return node
-	}
-	return node
-}
-
 func (e *WalkNodeEvaluator) startNode(pos cube.Pos) *pathfind.Node {
 	node := e.Node(pos)
 	node.Type = e.CachedBlockPathType(e.source, node.Pos)
@@ -193,28 +154,13 @@ func (e *WalkNodeEvaluator) startNode(pos cube.Pos) *pathfind.Node {
 	return node
 }
 
-func (e *WalkNodeEvaluator) Goal(pos cube.Pos) *pathfind.Target {
-	return e.TargetFromNode(e.Node(pos))
-}
-
 func (e *WalkNodeEvaluator) Neighbors(node *pathfind.Node) []*pathfind.Node {
-	var (
-		nodes         []*pathfind.Node
-		maxUpStep     int
-		pathType      = e.CachedBlockPathType(e.source, node.Pos)
-		pathTypeAbove = e.CachedBlockPathType(e.source, node.Add(cube.Pos{0, 1, 0}))
-	)
-
-	if e.pathTypeCostMap.PathfindingMalus(pathTypeAbove) >= 0 && path.STICKY_HONEY != pathType {
-		maxUpStep = int(max(1, e.maxUpStep))
-	}
-
-	floorLevel := e.floorLevel(node.Vec3())
-	horizontalNeighbors := map[cube.Face]*pathfind.Node{}
+	nodes := e.neighbors[:0]
+	o := e.originOf(node)
+	var horizontalNeighbors [6]*pathfind.Node
 
 	for _, side := range cube.HorizontalFaces() {
-		neighborPos := node.Side(side)
-		neighborNode := e.AcceptedNode(neighborPos, maxUpStep, floorLevel, side, pathType)
+		neighborNode := e.horizontalNeighbor(node, o, side)
 		horizontalNeighbors[side] = neighborNode
 		if neighborNode != nil && e.IsNeighborValid(neighborNode, node) {
 			nodes = append(nodes, neighborNode)
@@ -222,19 +168,232 @@ func (e *WalkNodeEvaluator) Neighbors(node *pathfind.Node) []*pathfind.Node {
 	}
 
 	for _, zFace := range []cube.Face{cube.FaceNorth, cube.FaceSouth} {
-		zFacePos := node.Side(zFace)
 		for _, xFace := range []cube.Face{cube.FaceEast, cube.FaceWest} {
-			diagonalPos := zFacePos.Side(xFace)
-			diagonalNode := e.AcceptedNode(diagonalPos, maxUpStep, floorLevel, zFace, pathType)
-			if diagonalNode != nil && e.IsDiagonalValid(node, horizontalNeighbors[xFace], horizontalNeighbors[zFace], diagonalNode) {
+			if diagonalNode := e.diagonalNeighbor(node, o, xFace, zFace, horizontalNeighbors); diagonalNode != nil {
 				nodes = append(nodes, diagonalNode)
 			}
 		}
 	}
 
+	if e.canClimb {
+		for _, face := range []cube.Face{cube.FaceUp, cube.FaceDown} {
+			if n := e.climbNeighbor(node, o, face); n != nil {
+				nodes = append(nodes, n)
+			}
+		}
+	}
+
+	e.neighbors = nodes
+	return nodes
+}
+
+// Neighbor returns the neighbor of the node at pos like Neighbors, or nil if the node has no neighbor there.
+// Only the moves needed to reach pos are checked.
+func (e *WalkNodeEvaluator) Neighbor(node *pathfind.Node, pos cube.Pos) *pathfind.Node {
+	d := pos.Sub(node.Pos)
+	if pathfind.Abs(d.X()) > 1 || pathfind.Abs(d.Z()) > 1 {
+		return nil
+	}
+	o := e.originOf(node)
+	xFace, zFace := cube.FaceEast, cube.FaceSouth
+	if d.X() < 0 {
+		xFace = cube.FaceWest
+	}
+	if d.Z() < 0 {
+		zFace = cube.FaceNorth
+	}
+
+	var n *pathfind.Node
+	switch {
+	case d.X() == 0 && d.Z() == 0:
+		if e.canClimb && pathfind.Abs(d.Y()) == 1 {
+			face := cube.FaceUp
+			if d.Y() < 0 {
+				face = cube.FaceDown
+			}
+			n = e.climbNeighbor(node, o, face)
+		}
+	case d.X() == 0 || d.Z() == 0:
+		side := xFace
+		if d.X() == 0 {
+			side = zFace
+		}
+		if n = e.horizontalNeighbor(node, o, side); n != nil && !e.IsNeighborValid(n, node) {
+			n = nil
+		}
+	default:
+		var horizontalNeighbors [6]*pathfind.Node
+		horizontalNeighbors[xFace] = e.horizontalNeighbor(node, o, xFace)
+		horizontalNeighbors[zFace] = e.horizontalNeighbor(node, o, zFace)
+		n = e.diagonalNeighbor(node, o, xFace, zFace, horizontalNeighbors)
+	}
+	if n == nil || n.Pos != pos {
+		return nil
+	}
+	return n
+}
+
+// origin holds the properties of a node that the neighbors reached from it depend on.
+type origin struct {
+	maxUpStep  int
+	floorLevel float64
+	pathType   path.BlockPathType
+}
+
+// originOf returns the origin of the node passed.
+func (e *WalkNodeEvaluator) originOf(node *pathfind.Node) origin {
+	o := origin{pathType: e.CachedBlockPathType(e.source, node.Pos), floorLevel: e.floorLevel(node.Vec3())}
+	pathTypeAbove := e.CachedBlockPathType(e.source, node.Add(cube.Pos{0, 1, 0}))
+	if e.pathTypeCostMap.PathfindingMalus(pathTypeAbove) >= 0 && path.STICKY_HONEY != o.pathType {
+		o.maxUpStep = int(max(1, e.maxUpStep))
+	}
+	return o
+}
+
+// horizontalNeighbor returns the node the entity reaches moving from the node through the side passed, or nil
+// if it cannot move there. The node returned may still be invalid according to IsNeighborValid.
+func (e *WalkNodeEvaluator) horizontalNeighbor(node *pathfind.Node, o origin, side cube.Face) *pathfind.Node {
+	neighborNode := e.AcceptedNode(node.Side(side), o.maxUpStep, o.floorLevel, side, o.pathType)
+	if neighborNode != nil && !e.canCross(node.Pos, neighborNode.Pos, side) {
+		return nil
+	}
+	return neighborNode
+}
+
+// diagonalNeighbor returns the node the entity reaches moving from the node through both faces passed, or nil
+// if it cannot move there. horizontalNeighbors must hold the results of horizontalNeighbor for both faces.
+func (e *WalkNodeEvaluator) diagonalNeighbor(node *pathfind.Node, o origin, xFace, zFace cube.Face, horizontalNeighbors [6]*pathfind.Node) *pathfind.Node {
+	diagonalNode := e.AcceptedNode(node.Side(zFace).Side(xFace), o.maxUpStep, o.floorLevel, zFace, o.pathType)
+	if diagonalNode != nil && e.IsDiagonalValid(node, horizontalNeighbors[xFace], horizontalNeighbors[zFace], diagonalNode) &&
+		e.canCross(node.Pos, diagonalNode.Pos, xFace) && e.canCross(node.Pos, diagonalNode.Pos, zFace) {
+		return diagonalNode
+	}
+	return nil
+}
+
+// climbNeighbor returns the node the entity reaches climbing from the node in the direction of the face
+// passed, or nil if it cannot climb there. Entities can only climb up from climbable blocks.
+func (e *WalkNodeEvaluator) climbNeighbor(node *pathfind.Node, o origin, face cube.Face) *pathfind.Node {
+	if face == cube.FaceUp && o.pathType != path.CLIMBABLE {
+		return nil
+	}
+	if n := e.climbableNode(node.Side(face)); n != nil && e.IsNeighborValid(n, node) {
+		return n
+	}
+	return nil
+}
+
+// canCross checks if the entity can leave the block at from through the face passed and enter the block next
+// to it from the opposite side. If the entity steps up, the block it steps up to is entered from the side too,
+// while blocks fallen into are entered from above. Open trapdoors cannot be crossed through their panel, and
+// closed fence gates can only be passed in the direction they swing open.
+func (e *WalkNodeEvaluator) canCross(from, to cube.Pos, face cube.Face) bool {
+	level := cube.Pos{to.X(), from.Y(), to.Z()}
+	if !faceCrossable(e.source.Block(from), face) || !faceCrossable(e.source.Block(level), face.Opposite()) {
+		return false
+	}
+	return to.Y() <= from.Y() || faceCrossable(e.source.Block(to), face.Opposite())
+}
+
+// faceCrossable checks if an entity can move through the face of the block passed.
+func faceCrossable(bl world.Block, face cube.Face) bool {
+	switch b := bl.(type) {
+	case block.WoodTrapdoor:
+		return !b.Open || b.Facing.Face().Opposite() != face
+	case block.CopperTrapdoor:
+		return !b.Open || b.Facing.Face().Opposite() != face
+	case block.WoodFenceGate:
+		return b.Open || b.Facing.Face().Axis() == face.Axis()
+	}
+	return true
+}
+
+// PredecessorCandidates returns the nodes the entity may step up, fall or climb to the node from.
+func (e *WalkNodeEvaluator) PredecessorCandidates(node *pathfind.Node) []*pathfind.Node {
+	var nodes []*pathfind.Node
+	maxUpStep := int(max(1, e.maxUpStep))
+	for x := -1; x <= 1; x++ {
+		for z := -1; z <= 1; z++ {
+			if x == 0 && z == 0 {
+				continue
+			}
+			for y := -maxUpStep; y <= e.maxFallDistance; y++ {
+				if pos := node.Add(cube.Pos{x, y, z}); e.standable(pos) {
+					nodes = append(nodes, e.Node(pos))
+				}
+			}
+		}
+	}
+	if e.canClimb {
+		for _, face := range []cube.Face{cube.FaceUp, cube.FaceDown} {
+			if pos := node.Side(face); e.standable(pos) {
+				nodes = append(nodes, e.Node(pos))
+			}
+		}
+	}
 	return nodes
 }
 
+// standable checks if a node at the position could be reached by the entity.
+func (e *WalkNodeEvaluator) standable(pos cube.Pos) bool {
+	pathType := e.CachedBlockPathType(e.source, pos)
+	return pathType != path.OPEN && e.pathTypeCostMap.PathfindingMalus(pathType) >= 0
+}
+
+// climbableNode returns node from position if the entity can climb through it.
+func (e *WalkNodeEvaluator) climbableNode(pos cube.Pos) *pathfind.Node {
+	pathType := e.CachedBlockPathType(e.source, pos)
+	if pathType != path.CLIMBABLE {
+		return nil
+	}
+	malus := e.pathTypeCostMap.PathfindingMalus(pathType)
+	if malus < 0 {
+		return nil
+	}
+	return e.nodeAndUpdateCostToMax(pos, pathType, malus)
+}
+
+// CanMoveDirectly checks if the entity can walk in a straight line from one node to another on the same
+// level, without colliding with blocks, stepping up or down the floor or crossing path types more costly
+// than the nodes.
+func (e *WalkNodeEvaluator) CanMoveDirectly(source world.BlockSource, from, to *pathfind.Node) bool {
+	if from.Y() != to.Y() {
+		return false
+	}
+	start := mgl64.Vec3{float64(from.X()) + 0.5, float64(from.Y()), float64(from.Z()) + 0.5}
+	end := mgl64.Vec3{float64(to.X()) + 0.5, float64(to.Y()), float64(to.Z()) + 0.5}
+	relativePos := end.Sub(start)
+
+	maxMalus := max(from.CostMalus, to.CostMalus)
+	stepCount := int(math.Ceil(relativePos.Len() / lineStepLength))
+	step := relativePos.Mul(1 / float64(stepCount))
+	floor := FloorLevelAt(source, start)
+
+	previous := from.Pos
+	for i := 1; i < stepCount; i++ {
+		point := start.Add(step.Mul(float64(i)))
+		if math.Abs(FloorLevelAt(source, point)-floor) > lineMaxFloorStep {
+			return false
+		}
+		pos := cube.PosFromVec3(point)
+		if pos == previous {
+			continue
+		}
+		previous = pos
+
+		pathType := e.blockPathTypeAt(source, pos)
+		if pathType != path.WALKABLE && pathType != from.Type && pathType != to.Type {
+			return false
+		}
+		if malus := e.pathTypeCostMap.PathfindingMalus(pathType); malus < 0 || malus > maxMalus {
+			return false
+		}
+	}
+
+	bb := e.entitySizeInfo.Translate(start.Add(mgl64.Vec3{0, lineFloorClearance}))
+	return canMoveWithoutCollision(source, bb, relativePos, lineStepLength)
+}
+
 // IsNeighborValid checks if neighbor valid for passed node.
 func (e *WalkNodeEvaluator) IsNeighborValid(neighbor, node *pathfind.Node) bool {
 	return !neighbor.Closed && (neighbor.CostMalus >= 0 || node.CostMalus < 0)
@@ -282,7 +441,7 @@ func (e *WalkNodeEvaluator) AcceptedNode(pos cube.Pos, remainingJumpHeight int,
 		resultNode = nil
 	}
 
-	if currentPathType != path.WALKABLE && currentPathType != path.WATER {
+	if currentPathType != path.WALKABLE {
 		if (resultNode == nil || resultNode.CostMalus < 0) && remainingJumpHeight > 0 &&
 			(currentPathType != path.FENCE || e.canWalkOverFences) &&
 			currentPathType != path.UNPASSABLE_RAIL &&
@@ -291,25 +450,26 @@ func (e *WalkNodeEvaluator) AcceptedNode(pos cube.Pos, remainingJumpHeight int,
 			resultNode = e.AcceptedNode(pos.Add(cube.Pos{0, 1, 0}), remainingJumpHeight-1, floorLevel, facing, originPathType)
 			width := e.entitySizeInfo.Width()
 			if resultNode != nil && (resultNode.Type == path.OPEN || resultNode.Type == path.WALKABLE) && width < 1 {
+				// Check that the entity has room to jump up from the block it steps from.
 				halfWidth := width / 2
-				sidePos := pos.Side(facing).Vec3Middle()
-				y1 := e.floorLevel(sidePos.Add(mgl64.Vec3{0, 1, 0}))
+				originPos := pos.Side(facing.Opposite()).Vec3Middle()
+				y1 := e.floorLevel(originPos.Add(mgl64.Vec3{0, 1, 0}))
 				y2 := e.floorLevel(resultNode.Vec3())
 				bb := cube.Box(
-					sidePos.X()-halfWidth,
+					originPos.X()-halfWidth,
 					min(y1, y2)+0.001,
-					sidePos.Z()-halfWidth,
-					sidePos.X()+halfWidth,
+					originPos.Z()-halfWidth,
+					originPos.X()+halfWidth,
 					e.entitySizeInfo.Height()+max(y1, y2)-0.002,
-					sidePos.Z()+halfWidth,
+					originPos.Z()+halfWidth,
 				)
 				if e.hasCollisions(bb) {
 					resultNode = nil
 				}
 			}
 		}
-		if !e.canFloat {
-			if e.CachedBlockPathType(e.source, pos.Sub(cube.Pos{0, 1, 0})) == path.WATER {
+		if currentPathType == path.WATER && !e.canFloat {
+			if e.CachedBlockPathType(e.source, pos.Sub(cube.Pos{0, 1, 0})) != path.WATER {
 				return resultNode
 			}
 
@@ -333,7 +493,7 @@ func (e *WalkNodeEvaluator) AcceptedNode(pos cube.Pos, remainingJumpHeight int,
 				}
 
 				fallDistance++
-				if fallDistance >= e.maxFallDistance {
+				if fallDistance > e.maxFallDistance {
 					return e.blockedNode(pos)
 				}
 
@@ -364,78 +524,9 @@ func (e *WalkNodeEvaluator) AcceptedNode(pos cube.Pos, remainingJumpHeight int,
 	return resultNode
 }
 
-// blockedNode returns new blocked pathfind.Node.
-func (e *WalkNodeEvaluator) blockedNode(pos cube.Pos) *pathfind.Node {
-	node := e.Node(pos)
-	node.Type = path.BLOCKED
-	node.CostMalus = -1
-
-	return node
-}
-
-// canReachWithoutCollision ...
-func (e *WalkNodeEvaluator) canReachWithoutCollision(node *pathfind.Node) (result bool) {
-	bb := e.boundingBox
-	mobPos := e.startPosition
-
-	relativePos := mgl64.Vec3{
-		float64(node.X()-mobPos.X()) + bb.Width()/2,
-		float64(node.Y()-mobPos.Y()) + bb.Height()/2,
-		float64(node.Z()-mobPos.Z()) + bb.Length()/2,
-	}
-
-	stepCount := int(math.Ceil(relativePos.Len() / pathfind.AverageEdgeLength(bb)))
-	relativePos = relativePos.Mul(1 / float64(stepCount))
-
-	for i := 1; i <= stepCount; i++ {
-		bb = bb.Translate(relativePos)
-		if e.hasCollisions(bb) {
-			return false
-		}
-	}
-	return true
-}
-
-// hasCollisions ...
-func (e *WalkNodeEvaluator) hasCollisions(bb cube.BBox) (result bool) {
-	Max := cube.PosFromVec3(bb.Max()).Add(cube.Pos{1, 1, 1})
-	Min := cube.PosFromVec3(bb.Min()).Sub(cube.Pos{1, 1, 1})
-	for z := Min.Z(); z <= Max.Z(); z++ {
-		for x := Min.X(); x <= Max.X(); x++ {
-			for y := Min.Y(); y <= Max.Y(); y++ {
-				pos := cube.Pos{x, y, z}
-				bl := e.source.Block(pos)
-				for _, box := range bl.Model().BBox(pos, e.source) {
-					if box.IntersectsWith(bb) {
-						_, solid := bl.Model().(model.Solid)
-						if solid {
-							return true
-						}
-					}
-
-				}
-			}
-		}
-	}
-	return false
-}
-
 // BlockHavePartialCollision ...
 func BlockHavePartialCollision(pathType path.BlockPathType) bool {
-	switch pathType {
-	case path.FENCE, path.DOOR_WOOD_CLOSED, path.DOOR_IRON_CLOSED:
-		return true
-	default:
-		return false
-	}
-}
-
-// nodeAndUpdateCostToMax ...
-func (e *WalkNodeEvaluator) nodeAndUpdateCostToMax(pos cube.Pos, pathType path.BlockPathType, malus float64) *pathfind.Node {
-	node := e.Node(pos)
-	node.Type = pathType
-	node.CostMalus = max(node.CostMalus, malus)
-	return node
+	return pathType.PartialCollision()
 }
 
 // mobJumpHeight ...
@@ -451,6 +542,9 @@ func (e *WalkNodeEvaluator) floorLevel(pos mgl64.Vec3) float64 {
 			return pos[1] + 0.5
 		}
 	}
+	if e.canClimb && e.CachedBlockPathType(e.source, cube.PosFromVec3(pos)) == path.CLIMBABLE {
+		return pos[1]
+	}
 	return FloorLevelAt(e.source, pos)
 }
 
@@ -458,6 +552,15 @@ func (e *WalkNodeEvaluator) floorLevel(pos mgl64.Vec3) float64 {
 func FloorLevelAt(source world.BlockSource, pos mgl64.Vec3) (result float64) {
 	down := pos.Sub(mgl64.Vec3{0, 1, 0})
 
+	// Full and empty blocks are the most common floors, so they are handled without tracing.
+	downPos := cube.PosFromVec3(down)
+	switch source.Block(downPos).Model().(type) {
+	case model.Solid:
+		return float64(downPos.Y() + 1)
+	case model.Empty:
+		return down.Y()
+	}
+
 	defer func() {
 		e := recover()
 		if e != nil {
@@ -472,62 +575,39 @@ func FloorLevelAt(source world.BlockSource, pos mgl64.Vec3) (result float64) {
 	return traceResult.Position().Y()
 }
 
+// BlockPathTypes returns path.BlockPathType at pos and all path types in the space occupied by the entity.
 func (e *WalkNodeEvaluator) BlockPathTypes(source world.BlockSource, pos cube.Pos, pathType path.BlockPathType, mobPos cube.Pos) (path.BlockPathType, []path.BlockPathType) {
-	var pathTypes []path.BlockPathType
-
-	var entityWidth, entityHeight, entityDepth = e.entitySizeInfo.widthInt(), e.entitySizeInfo.heightInt(), e.entitySizeInfo.depthInt()
-	for currentX := 0; currentX < entityWidth; currentX++ {
-		for currentY := 0; currentY < entityHeight; currentY++ {
-			for currentZ := 0; currentZ < entityDepth; currentZ++ {
-				currentPathType := e.evaluateBlockPathType(source, mobPos, BlockPathType(source, pos.Add(cube.Pos{currentX, currentY, currentZ})))
-				if currentX == 0 && currentY == 0 && currentZ == 0 {
-					pathType = currentPathType
-				}
-				if !slices.Contains(pathTypes, currentPathType) {
-					pathTypes = append(pathTypes, currentPathType)
-				}
-			}
-		}
-	}
-	slices.Sort(pathTypes)
-	return pathType, pathTypes
+	return e.blockPathTypes(source, pos, pathType, mobPos, e.classify)
 }
 
-func (e *WalkNodeEvaluator) evaluateBlockPathType(source world.BlockSource, mobPos cube.Pos, pathType path.BlockPathType) path.BlockPathType {
-	canPassDoors := e.canPassDoors
-	if pathType == path.DOOR_WOOD_CLOSED && e.canPassDoors && canPassDoors {
-		pathType = path.WALKABLE_DOOR
-	} else if pathType == path.DOOR_OPEN && canPassDoors {
-		pathType = path.BLOCKED
+// FlatNode returns the node at pos and the level of its floor if the entity can walk there without a cost
+// malus, so that pathfind.JumpPointEvaluator can skip expanding nodes on flat ground. Otherwise, obstacle
+// reports if pos and the position above it are both blocked, so that the entity cannot step up or fall there.
+func (e *WalkNodeEvaluator) FlatNode(pos cube.Pos) (node *pathfind.Node, floor float64, obstacle bool) {
+	pathType := e.CachedBlockPathType(e.source, pos)
+	if pathType != path.WALKABLE {
+		obstacle = e.pathTypeCostMap.PathfindingMalus(pathType) < 0 &&
+			e.pathTypeCostMap.PathfindingMalus(e.CachedBlockPathType(e.source, pos.Side(cube.FaceUp))) < 0
+		return nil, 0, obstacle
 	}
-	//else if pathType == path_type.RAIL {
-	//
-	//}
-	return pathType
-}
-
-// CachedBlockPathType returns cached path.BlockPathType from position.
-func (e *WalkNodeEvaluator) CachedBlockPathType(source world.BlockSource, pos cube.Pos) path.BlockPathType {
-	t, has := e.pathTypesByPosCache[pos]
-	if !has {
-		t = e.blockPathTypeAt(source, pos)
-		e.pathTypesByPosCache[pos] = t
+	if e.pathTypeCostMap.PathfindingMalus(path.WALKABLE) != 0 {
+		return nil, 0, false
 	}
-	return t
-}
-
-// isEntityUnderwater ...
-func (e *WalkNodeEvaluator) isEntityUnderwater() bool {
-	start := e.startPosition
-	start[1] += e.entitySizeInfo.heightInt()
-	bl, water := e.source.Block(start).(block.Water)
-	if !water {
-		return false
+	node = e.nodeAndUpdateCostToMax(pos, path.WALKABLE, 0)
+	if node.CostMalus != 0 {
+		return nil, 0, false
 	}
+	return node, e.floorLevel(pos.Vec3()), false
+}
 
-	f := float64(start[1]+1) - (waterDepthPercent(bl) - 0.1111111)
+// ValidNode checks if a node of an existing path can still be passed in the source passed.
+func (e *WalkNodeEvaluator) ValidNode(source world.BlockSource, node *pathfind.Node) bool {
+	return e.validPathType(node, e.blockPathTypeAt(source, node.Pos))
+}
 
-	return (float64(e.startPosition[1]) + e.entitySizeInfo.Height()) < f
+// CachedBlockPathType returns cached path.BlockPathType from position.
+func (e *WalkNodeEvaluator) CachedBlockPathType(source world.BlockSource, pos cube.Pos) path.BlockPathType {
+	return e.cachedBlockPathType(source, pos, e.blockPathTypeAt)
 }
 
 // blockPathTypeAt returns  path.BlockPathType for passed position.
@@ -559,13 +639,34 @@ func (e *WalkNodeEvaluator) blockPathTypeAt(source world.BlockSource, pos cube.P
 // BlockPathType returns path.BlockPathType for passed position.
 func BlockPathType(source world.BlockSource, pos cube.Pos) path.BlockPathType {
 	pathType := BlockPathTypeRaw(source, pos)
-	if pathType == path.OPEN && pos[1] >= (-64+1) {
+	switch pathType {
+	case path.OPEN, path.SLOW, path.TRIPWIRE, path.PRESSURE_PLATE, path.DAMAGE_CAUTIOUS:
+		return openBlockPathType(source, pos, pathType)
+	}
+	if pathType == path.WALKABLE {
+		pathType = CheckNeighbourBlocks(source, pos, pathType)
+	}
+	return pathType
+}
+
+// openBlockPathType returns the path.BlockPathType of a position the entity can move through, which depends
+// on the floor below it and the blocks around it. Blocks without collision that have a path type of their own,
+// like cobwebs and tripwires, only keep it over a floor, as entities cannot stand in them mid-air.
+func openBlockPathType(source world.BlockSource, pos cube.Pos, rawType path.BlockPathType) path.BlockPathType {
+	pathType := path.OPEN
+	if pos[1] >= (-64 + 1) {
 		position := pos
 		position[1]--
 		pathTypeDown := BlockPathTypeRaw(source, position)
 
-		if pathTypeDown != path.WALKABLE && pathTypeDown != path.OPEN && pathTypeDown != path.WATER && pathTypeDown != path.LAVA {
+		if pathTypeDown != path.WALKABLE && pathTypeDown != path.OPEN && pathTypeDown != path.WATER && pathTypeDown != path.LAVA && pathTypeDown != path.CLIMBABLE &&
+			pathTypeDown != path.PRESSURE_PLATE && pathTypeDown != path.TRIPWIRE && pathTypeDown != path.SLOW {
 			pathType = path.WALKABLE
+			if floorType, ok := floorPathType(source.Block(position)); ok {
+				pathType = floorType
+			} else if rawType != path.OPEN {
+				pathType = rawType
+			}
 		} else {
 			pathType = path.OPEN
 		}
@@ -583,7 +684,7 @@ func BlockPathType(source world.BlockSource, pos cube.Pos) path.BlockPathType {
 		}
 	}
 
-	if pathType == path.WALKABLE {
+	if pathType == path.WALKABLE || pathType == path.SLOW {
 		pathType = CheckNeighbourBlocks(source, pos, pathType)
 	}
 	return pathType
@@ -594,17 +695,20 @@ func CheckNeighbourBlocks(source world.BlockSource, pos cube.Pos, pathType path.
 	for currentX := -1; currentX <= 1; currentX++ {
 		for currentY := -1; currentY <= 1; currentY++ {
 			for currentZ := -1; currentZ <= 1; currentZ++ {
-				switch 0 {
-				case currentX, currentY, currentZ:
+				if currentX == 0 && currentZ == 0 {
 					continue
 				}
 				bl := source.Block(pos.Add(cube.Pos{currentX, currentY, currentZ}))
 
-				switch bl.(type) {
+				switch b := bl.(type) {
 				case block.Cactus:
 					return path.DANGER_OTHER
-				case block.Lava, block.Fire, block.Campfire:
+				case block.Lava, block.Fire:
 					return path.DANGER_FIRE
+				case block.Campfire:
+					if !b.Extinguished {
+						return path.DANGER_FIRE
+					}
 				case block.Water:
 					return path.WATER_BORDER
 				}
@@ -617,12 +721,17 @@ func CheckNeighbourBlocks(source world.BlockSource, pos cube.Pos, pathType path.
 // BlockPathTypeRaw returns path.BlockPathType depending on the block.
 func BlockPathTypeRaw(source world.BlockSource, pos cube.Pos) path.BlockPathType {
 	bl := source.Block(pos)
+	if pathType, ok := registeredPathType(bl); ok {
+		return pathType
+	}
 
 	switch b := bl.(type) {
 	case block.Air:
 		return path.OPEN
-	case block.CopperTrapdoor, block.WoodTrapdoor:
-		return path.TRAPDOOR
+	case block.WoodTrapdoor:
+		return trapdoorPathType(b.Open, b.Top)
+	case block.CopperTrapdoor:
+		return trapdoorPathType(b.Open, b.Top)
 	case block.Cactus:
 		return path.DAMAGE_OTHER
 	case block.CocoaBean:
@@ -631,8 +740,17 @@ func BlockPathTypeRaw(source world.BlockSource, pos cube.Pos) path.BlockPathType
 		return path.WATER
 	case block.Lava:
 		return path.LAVA
-	case block.Fire, block.Campfire:
+	case block.Fire:
 		return path.DAMAGE_FIRE
+	case block.Campfire:
+		if !b.Extinguished {
+			return path.DAMAGE_FIRE
+		}
+		return path.BLOCKED
+	case block.Flower:
+		if b.Type == block.WitherRose() {
+			return path.DAMAGE_CAUTIOUS
+		}
 	case block.WoodDoor:
 		if !b.Open {
 			return path.DOOR_WOOD_CLOSED
@@ -645,19 +763,86 @@ func BlockPathTypeRaw(source world.BlockSource, pos cube.Pos) path.BlockPathType
 		return path.DOOR_OPEN
 	case block.Leaves:
 		return path.LEAVES
-	case block.WoodFence, block.Wall:
+	case block.Ladder:
+		return path.CLIMBABLE
+	case block.WoodFence, block.NetherBrickFence, block.Wall:
 		return path.FENCE
 	case block.WoodFenceGate:
 		if b.Open {
-			return path.BLOCKED
-		}
-		return path.OPEN
-	default:
-		if pathfind.ComputationTypeLand.Pathfindable(bl, source, pos) {
 			return path.OPEN
 		}
+		return path.FENCE_GATE_CLOSED
+	}
+	if pathType, ok := namedPathType(bl); ok {
+		return pathType
+	}
+	if pathfind.ComputationTypeLand.Pathfindable(bl, source, pos) {
+		return path.OPEN
+	}
+	return path.BLOCKED
+}
+
+// trapdoorPathType returns the path.BlockPathType of a trapdoor. Closed trapdoors in the bottom half of a
+// block are walked on like a floor, while those in the top half block the space like a top slab. Open
+// trapdoors only cover one side of the block, which is checked when moving through them.
+func trapdoorPathType(open, top bool) path.BlockPathType {
+	switch {
+	case open:
+		return path.OPEN
+	case top:
 		return path.BLOCKED
 	}
+	return path.TRAPDOOR
+}
+
+// namedBlockPathTypes holds the path types of blocks that have no implementation in Dragonfly by their
+// name, so that custom implementations of them are classified too.
+var namedBlockPathTypes = map[string]path.BlockPathType{
+	"minecraft:vine":                         path.CLIMBABLE,
+	"minecraft:twisting_vines":               path.CLIMBABLE,
+	"minecraft:weeping_vines":                path.CLIMBABLE,
+	"minecraft:cave_vines":                   path.CLIMBABLE,
+	"minecraft:cave_vines_body_with_berries": path.CLIMBABLE,
+	"minecraft:cave_vines_head_with_berries": path.CLIMBABLE,
+	"minecraft:scaffolding":                  path.CLIMBABLE,
+	"minecraft:trip_wire":                    path.TRIPWIRE,
+	"minecraft:sweet_berry_bush":             path.DAMAGE_OTHER,
+	"minecraft:pointed_dripstone":            path.DAMAGE_CAUTIOUS,
+	"minecraft:web":                          path.SLOW,
+	"minecraft:powder_snow":                  path.POWDER_SNOW,
+	"minecraft:honey_block":                  path.BLOCKED,
+	"minecraft:magma":                        path.BLOCKED,
+}
+
+// namedPathType returns the path.BlockPathType of blocks that have no implementation in Dragonfly by their
+// name, so that custom implementations of them are classified too.
+func namedPathType(bl world.Block) (path.BlockPathType, bool) {
+	name, _ := bl.EncodeBlock()
+	if pathType, ok := namedBlockPathTypes[name]; ok {
+		return pathType, true
+	}
+	if strings.HasSuffix(name, "_pressure_plate") {
+		return path.PRESSURE_PLATE, true
+	}
+	return 0, false
+}
+
+// floorPathType returns the path.BlockPathType of standing on the block passed, if it differs from
+// path.WALKABLE. Blocks registered with a path type created by path.Register give it to the position above.
+func floorPathType(bl world.Block) (path.BlockPathType, bool) {
+	if pathType, ok := registeredPathType(bl); ok && pathType.Registered() {
+		return pathType, true
+	}
+	if _, ok := bl.(block.SoulSand); ok {
+		return path.SLOW, true
+	}
+	switch name, _ := bl.EncodeBlock(); name {
+	case "minecraft:magma":
+		return path.DAMAGE_FIRE, true
+	case "minecraft:honey_block":
+		return path.STICKY_HONEY, true
+	}
+	return 0, false
 }
 
 func isSourceWaterBlock(bl block.Water) bool {
@@ -667,3 +852,14 @@ func isSourceWaterBlock(bl block.Water) bool {
 const (
 	DefaultMobJumpHeight = 1.125
 )
+
+const (
+	// lineStepLength is the distance between two checks of a straight line movement.
+	lineStepLength = 0.25
+	// lineFloorClearance is the height the bounding box is lifted by when checking straight line movement,
+	// so that it does not collide with the floor.
+	lineFloorClearance = 0.01
+	// lineMaxFloorStep is the max difference of the floor level along a straight line movement, so that it
+	// does not include jumps or falls.
+	lineMaxFloorStep = 0.5
+)
@@ -0,0 +1,580 @@
+package evaluator_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	pathfind "github.com/FDUTCH/Pathfinder"
+	"github.com/FDUTCH/Pathfinder/evaluator"
+	"github.com/FDUTCH/Pathfinder/internal/testworld"
+	"github.com/FDUTCH/Pathfinder/path"
+	"github.com/df-mc/dragonfly/server/block"
+	"github.com/df-mc/dragonfly/server/block/cube"
+	"github.com/df-mc/dragonfly/server/world"
+)
+
+// playerBox is the bounding box of a player sized entity.
+var playerBox = cube.Box(-0.3, 0, -0.3, 0.3, 1.8, 0.3)
+
+// neighbors returns the neighbors of the start node of an evaluator made from the config passed, by position.
+func neighbors(w *testworld.World, config evaluator.WalkNodeEvaluatorConfig, start cube.Pos) map[cube.Pos]*pathfind.Node {
+	if config.Box == (cube.BBox{}) {
+		config.Box = playerBox
+	}
+	e := config.New()
+	e.Prepare(w, start)
+	defer e.Done()
+
+	nodes := map[cube.Pos]*pathfind.Node{}
+	for _, n := range e.Neighbors(e.StartNode()) {
+		nodes[n.Pos] = n
+	}
+	return nodes
+}
+
+const flatFloor = `
+#####
+#####
+#####
+#####
+#####`
+
+// middleRow returns a layer five blocks wide and deep that only holds the row passed at z=2.
+func middleRow(row string) string {
+	return strings.Join([]string{".....", ".....", row, ".....", "....."}, "\n")
+}
+
+// doorway returns a layer with a wall at x=3 that has the door passed at z=2.
+func doorway(door string) string {
+	return strings.Join([]string{"...#.", "...#.", "..." + door + ".", "...#.", "...#."}, "\n")
+}
+
+func TestWalkNodeEvaluatorNeighbors(t *testing.T) {
+	tests := []struct {
+		name   string
+		layers []string
+		// blocks holds blocks placed after the layers are parsed.
+		blocks map[cube.Pos]world.Block
+		config evaluator.WalkNodeEvaluatorConfig
+		start  cube.Pos
+		// want holds the neighbors expected and their path type.
+		want map[cube.Pos]path.BlockPathType
+		// blocked holds columns, by x and z, that no neighbor may be in.
+		blocked [][2]int
+	}{
+		{
+			name:   "flat ground",
+			layers: []string{flatFloor},
+			start:  cube.Pos{2, 1, 2},
+			want: map[cube.Pos]path.BlockPathType{
+				{1, 1, 1}: path.WALKABLE, {2, 1, 1}: path.WALKABLE, {3, 1, 1}: path.WALKABLE,
+				{1, 1, 2}: path.WALKABLE, {3, 1, 2}: path.WALKABLE,
+				{1, 1, 3}: path.WALKABLE, {2, 1, 3}: path.WALKABLE, {3, 1, 3}: path.WALKABLE,
+			},
+		},
+		{
+			name:   "step up",
+			layers: []string{flatFloor, middleRow("...#.")},
+			start:  cube.Pos{2, 1, 2},
+			want:   map[cube.Pos]path.BlockPathType{{3, 2, 2}: path.WALKABLE},
+		},
+		{
+			name:    "step up under ceiling",
+			layers:  []string{flatFloor, middleRow("...#."), middleRow("....."), middleRow("..#..")},
+			start:   cube.Pos{2, 1, 2},
+			blocked: [][2]int{{3, 2}},
+		},
+		{
+			name:   "step up under carpet out of reach",
+			layers: []string{flatFloor, middleRow("...#.")},
+			blocks: map[cube.Pos]world.Block{{2, 4, 2}: block.Carpet{}},
+			start:  cube.Pos{2, 1, 2},
+			want:   map[cube.Pos]path.BlockPathType{{3, 2, 2}: path.WALKABLE},
+		},
+		{
+			// Carpets leave room to stand below them and are not model.Solid, but their collision box leaves no
+			// room to jump up.
+			name:    "step up under carpet",
+			layers:  []string{flatFloor, middleRow("...#.")},
+			blocks:  map[cube.Pos]world.Block{{2, 3, 2}: block.Carpet{}},
+			start:   cube.Pos{2, 1, 2},
+			blocked: [][2]int{{3, 2}},
+		},
+		{
+			name:    "wall",
+			layers:  []string{flatFloor, middleRow("...#."), middleRow("...#.")},
+			start:   cube.Pos{2, 1, 2},
+			blocked: [][2]int{{3, 2}},
+		},
+		{
+			name:    "fence",
+			layers:  []string{flatFloor, middleRow("...F.")},
+			start:   cube.Pos{2, 1, 2},
+			blocked: [][2]int{{3, 2}},
+		},
+		{
+			name:    "wall block",
+			layers:  []string{flatFloor, middleRow("...W.")},
+			start:   cube.Pos{2, 1, 2},
+			blocked: [][2]int{{3, 2}},
+		},
+		{
+			name:    "closed wood door",
+			layers:  []string{flatFloor, doorway("D"), doorway("D")},
+			start:   cube.Pos{2, 1, 2},
+			blocked: [][2]int{{3, 2}},
+		},
+		{
+			name:    "closed wood door without opening",
+			layers:  []string{flatFloor, doorway("D"), doorway("D")},
+			config:  evaluator.WalkNodeEvaluatorConfig{CanPathDoors: true},
+			start:   cube.Pos{2, 1, 2},
+			blocked: [][2]int{{3, 2}},
+		},
+		{
+			name:   "closed wood door opened",
+			layers: []string{flatFloor, doorway("D"), doorway("D")},
+			config: evaluator.WalkNodeEvaluatorConfig{CanPathDoors: true, CanOpenDoors: true},
+			start:  cube.Pos{2, 1, 2},
+			want:   map[cube.Pos]path.BlockPathType{{3, 1, 2}: path.WALKABLE_DOOR},
+		},
+		{
+			name:   "closed wood door broken",
+			layers: []string{flatFloor, doorway("D"), doorway("D")},
+			config: evaluator.WalkNodeEvaluatorConfig{CanPathDoors: true, CanBreakDoors: true},
+			start:  cube.Pos{2, 1, 2},
+			want:   map[cube.Pos]path.BlockPathType{{3, 1, 2}: path.WALKABLE_DOOR},
+		},
+		{
+			name:    "open wood door",
+			layers:  []string{flatFloor, doorway("d"), doorway("d")},
+			start:   cube.Pos{2, 1, 2},
+			blocked: [][2]int{{3, 2}},
+		},
+		{
+			name:   "open wood door passed",
+			layers: []string{flatFloor, doorway("d"), doorway("d")},
+			config: evaluator.WalkNodeEvaluatorConfig{CanPathDoors: true},
+			start:  cube.Pos{2, 1, 2},
+			want:   map[cube.Pos]path.BlockPathType{{3, 1, 2}: path.DOOR_OPEN},
+		},
+		{
+			name:    "closed copper door",
+			layers:  []string{flatFloor, doorway("I"), doorway("I")},
+			config:  evaluator.WalkNodeEvaluatorConfig{CanPathDoors: true, CanOpenDoors: true, CanBreakDoors: true},
+			start:   cube.Pos{2, 1, 2},
+			blocked: [][2]int{{3, 2}},
+		},
+		{
+			name:    "closed fence gate",
+			layers:  []string{flatFloor},
+			blocks:  map[cube.Pos]world.Block{{3, 1, 2}: block.WoodFenceGate{Facing: cube.East}},
+			start:   cube.Pos{2, 1, 2},
+			blocked: [][2]int{{3, 2}},
+		},
+		{
+			name:   "closed fence gate opened",
+			layers: []string{flatFloor},
+			blocks: map[cube.Pos]world.Block{{3, 1, 2}: block.WoodFenceGate{Facing: cube.East}},
+			config: evaluator.WalkNodeEvaluatorConfig{CanOpenGates: true},
+			start:  cube.Pos{2, 1, 2},
+			want:   map[cube.Pos]path.BlockPathType{{3, 1, 2}: path.WALKABLE_GATE},
+		},
+		{
+			name:    "closed fence gate opened sideways",
+			layers:  []string{flatFloor},
+			blocks:  map[cube.Pos]world.Block{{3, 1, 2}: block.WoodFenceGate{Facing: cube.North}},
+			config:  evaluator.WalkNodeEvaluatorConfig{CanOpenGates: true},
+			start:   cube.Pos{2, 1, 2},
+			blocked: [][2]int{{3, 2}},
+		},
+		{
+			name:   "open fence gate",
+			layers: []string{flatFloor},
+			blocks: map[cube.Pos]world.Block{{3, 1, 2}: block.WoodFenceGate{Facing: cube.North, Open: true}},
+			start:  cube.Pos{2, 1, 2},
+			want:   map[cube.Pos]path.BlockPathType{{3, 1, 2}: path.WALKABLE},
+		},
+		{
+			name:   "open trapdoor",
+			layers: []string{flatFloor},
+			blocks: map[cube.Pos]world.Block{{3, 1, 2}: block.WoodTrapdoor{Facing: cube.West, Open: true}},
+			start:  cube.Pos{2, 1, 2},
+			want:   map[cube.Pos]path.BlockPathType{{3, 1, 2}: path.WALKABLE},
+		},
+		{
+			name:    "open trapdoor panel",
+			layers:  []string{flatFloor},
+			blocks:  map[cube.Pos]world.Block{{3, 1, 2}: block.WoodTrapdoor{Facing: cube.East, Open: true}},
+			start:   cube.Pos{2, 1, 2},
+			blocked: [][2]int{{3, 2}},
+		},
+		{
+			name:   "open trapdoor in floor",
+			layers: []string{flatFloor},
+			blocks: map[cube.Pos]world.Block{{3, 0, 2}: block.WoodTrapdoor{Facing: cube.East, Open: true}, {3, -1, 2}: block.Stone{}},
+			start:  cube.Pos{2, 1, 2},
+			want:   map[cube.Pos]path.BlockPathType{{3, 0, 2}: path.WALKABLE},
+		},
+		{
+			name:   "pressure plate",
+			layers: []string{flatFloor},
+			blocks: map[cube.Pos]world.Block{{3, 1, 2}: namedBlock("minecraft:stone_pressure_plate")},
+			start:  cube.Pos{2, 1, 2},
+			want:   map[cube.Pos]path.BlockPathType{{3, 1, 2}: path.PRESSURE_PLATE},
+		},
+		{
+			name:    "pressure plate avoided",
+			layers:  []string{flatFloor},
+			blocks:  map[cube.Pos]world.Block{{3, 1, 2}: namedBlock("minecraft:stone_pressure_plate")},
+			config:  evaluator.WalkNodeEvaluatorConfig{CostMap: path.CostMap{path.PRESSURE_PLATE: -1}},
+			start:   cube.Pos{2, 1, 2},
+			blocked: [][2]int{{3, 2}},
+		},
+		{
+			name:   "tripwire",
+			layers: []string{flatFloor},
+			blocks: map[cube.Pos]world.Block{{3, 1, 2}: namedBlock("minecraft:trip_wire")},
+			start:  cube.Pos{2, 1, 2},
+			want:   map[cube.Pos]path.BlockPathType{{3, 1, 2}: path.TRIPWIRE},
+		},
+		{
+			name:   "trapdoor",
+			layers: []string{flatFloor, middleRow("...T.")},
+			start:  cube.Pos{2, 1, 2},
+			want:   map[cube.Pos]path.BlockPathType{{3, 1, 2}: path.TRAPDOOR},
+		},
+		{
+			name:   "next to water",
+			layers: []string{flatFloor, middleRow("....~")},
+			start:  cube.Pos{2, 1, 2},
+			want:   map[cube.Pos]path.BlockPathType{{3, 1, 2}: path.WATER_BORDER},
+		},
+		{
+			name:    "lava",
+			layers:  []string{flatFloor, middleRow("...L.")},
+			start:   cube.Pos{2, 1, 2},
+			blocked: [][2]int{{3, 2}},
+		},
+		{
+			name:    "cactus",
+			layers:  []string{flatFloor, middleRow("...C.")},
+			start:   cube.Pos{2, 1, 2},
+			blocked: [][2]int{{3, 2}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := testworld.New(tt.layers...)
+			for pos, b := range tt.blocks {
+				w.Set(pos, b)
+			}
+			nodes := neighbors(w, tt.config, tt.start)
+			for pos, pathType := range tt.want {
+				n, ok := nodes[pos]
+				if !ok {
+					t.Errorf("no neighbor at %v", pos)
+					continue
+				}
+				if n.Type != pathType {
+					t.Errorf("neighbor at %v has path type %v, want %v", pos, n.Type, pathType)
+				}
+			}
+			for _, column := range tt.blocked {
+				for pos := range nodes {
+					if pos.X() == column[0] && pos.Z() == column[1] {
+						t.Errorf("unexpected neighbor at %v", pos)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestWalkNodeEvaluatorDoorAction(t *testing.T) {
+	tests := []struct {
+		name   string
+		config evaluator.WalkNodeEvaluatorConfig
+		want   pathfind.NodeAction
+	}{
+		{name: "open", config: evaluator.WalkNodeEvaluatorConfig{CanPathDoors: true, CanOpenDoors: true}, want: pathfind.ActionOpenDoor},
+		{name: "break", config: evaluator.WalkNodeEvaluatorConfig{CanPathDoors: true, CanBreakDoors: true}, want: pathfind.ActionBreakDoor},
+		{name: "open rather than break", config: evaluator.WalkNodeEvaluatorConfig{CanPathDoors: true, CanOpenDoors: true, CanBreakDoors: true}, want: pathfind.ActionOpenDoor},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes := neighbors(testworld.New(flatFloor, doorway("D"), doorway("D")), tt.config, cube.Pos{2, 1, 2})
+			n, ok := nodes[cube.Pos{3, 1, 2}]
+			if !ok {
+				t.Fatalf("no neighbor at the door")
+			}
+			if n.Action != tt.want {
+				t.Errorf("neighbor has action %v, want %v", n.Action, tt.want)
+			}
+			if n := nodes[cube.Pos{1, 1, 2}]; n.Action != pathfind.ActionNone {
+				t.Errorf("neighbor away from the door has action %v, want none", n.Action)
+			}
+		})
+	}
+}
+
+func TestWalkNodeEvaluatorGateAction(t *testing.T) {
+	w := testworld.New(flatFloor)
+	w.Set(cube.Pos{3, 1, 2}, block.WoodFenceGate{Facing: cube.East})
+	n, ok := neighbors(w, evaluator.WalkNodeEvaluatorConfig{CanOpenGates: true}, cube.Pos{2, 1, 2})[cube.Pos{3, 1, 2}]
+	if !ok {
+		t.Fatalf("no neighbor at the gate")
+	}
+	if n.Action != pathfind.ActionOpenGate {
+		t.Errorf("neighbor has action %v, want %v", n.Action, pathfind.ActionOpenGate)
+	}
+}
+
+func TestWalkNodeEvaluatorFall(t *testing.T) {
+	tests := []struct {
+		drop            int
+		maxFallDistance int
+		want            bool
+	}{
+		{drop: 1, maxFallDistance: 3, want: true},
+		{drop: 2, maxFallDistance: 3, want: true},
+		{drop: 3, maxFallDistance: 3, want: true},
+		{drop: 4, maxFallDistance: 3, want: false},
+		{drop: 5, maxFallDistance: 5, want: true},
+		{drop: 6, maxFallDistance: 5, want: false},
+	}
+	for _, tt := range tests {
+		w := testworld.New()
+		w.Fill(cube.Pos{0, 0, 0}, cube.Pos{4, 0, 4}, block.Stone{})
+		w.Fill(cube.Pos{0, 1, 0}, cube.Pos{1, tt.drop, 4}, block.Stone{})
+		start := cube.Pos{1, tt.drop + 1, 2}
+
+		nodes := neighbors(w, evaluator.WalkNodeEvaluatorConfig{MaxFallDistance: tt.maxFallDistance}, start)
+		n, ok := nodes[cube.Pos{2, 1, 2}]
+		if got := ok && n.CostMalus >= 0; got != tt.want {
+			t.Errorf("drop of %v with max fall distance %v: reachable = %v, want %v", tt.drop, tt.maxFallDistance, got, tt.want)
+		}
+	}
+}
+
+func TestWalkNodeEvaluatorAcceptedNode(t *testing.T) {
+	w := testworld.New(flatFloor, ".....\n.....\n...#.\n....#\n.....", ".....\n.....\n.....\n....#\n.....")
+	e := evaluator.WalkNodeEvaluatorConfig{Box: playerBox}.New()
+	e.Prepare(w, cube.Pos{2, 1, 2})
+	defer e.Done()
+
+	tests := []struct {
+		name string
+		pos  cube.Pos
+		want cube.Pos
+		ok   bool
+	}{
+		{name: "same level", pos: cube.Pos{2, 1, 1}, want: cube.Pos{2, 1, 1}, ok: true},
+		{name: "step up", pos: cube.Pos{3, 1, 2}, want: cube.Pos{3, 2, 2}, ok: true},
+		{name: "two blocks up", pos: cube.Pos{4, 1, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := e.AcceptedNode(tt.pos, 1, 1, cube.FaceEast, path.WALKABLE)
+			if ok := n != nil && n.CostMalus >= 0; ok != tt.ok {
+				t.Fatalf("AcceptedNode() accepted = %v, want %v", ok, tt.ok)
+			}
+			if tt.ok && n.Pos != tt.want {
+				t.Errorf("AcceptedNode() = %v, want %v", n.Pos, tt.want)
+			}
+		})
+	}
+}
+
+func TestWalkNodeEvaluatorWater(t *testing.T) {
+	// A pool two blocks deep next to a bank the entity stands on.
+	w := testworld.New()
+	w.Fill(cube.Pos{0, 0, 0}, cube.Pos{5, 0, 4}, block.Stone{})
+	w.Fill(cube.Pos{0, 1, 0}, cube.Pos{1, 2, 4}, block.Stone{})
+	w.Fill(cube.Pos{2, 1, 0}, cube.Pos{5, 2, 4}, block.Water{Still: true, Depth: 8})
+
+	tests := []struct {
+		name     string
+		canFloat bool
+		want     cube.Pos
+	}{
+		{name: "floating", canFloat: true, want: cube.Pos{3, 2, 2}},
+		{name: "sinking", canFloat: false, want: cube.Pos{3, 1, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := evaluator.WalkNodeEvaluatorConfig{Box: playerBox, CanFloat: tt.canFloat}.New()
+			e.Prepare(w, cube.Pos{2, 2, 2})
+			defer e.Done()
+
+			var found bool
+			for _, n := range e.Neighbors(e.Node(cube.Pos{2, 2, 2})) {
+				if n.X() == 3 && n.Z() == 2 {
+					found = true
+					if n.Pos != tt.want || n.Type != path.WATER {
+						t.Errorf("neighbor = %v with path type %v, want %v with path type %v", n.Pos, n.Type, tt.want, path.WATER)
+					}
+				}
+			}
+			if !found {
+				t.Errorf("no neighbor in the water")
+			}
+		})
+	}
+}
+
+func TestWalkNodeEvaluatorChasm(t *testing.T) {
+	// Chasms three blocks wide with blocks without collision at walking height, which cannot be stood in
+	// mid-air.
+	for name, row := range map[string]string{"cobwebs": "..www..", "tripwire": "..rrr.."} {
+		t.Run(name, func(t *testing.T) {
+			layer := row + "\n" + row + "\n" + row
+			w := testworld.Parse(legend, "##...##\n##...##\n##...##", layer)
+			e := evaluator.WalkNodeEvaluatorConfig{Box: playerBox}.New()
+			_, err := pathfind.FindPathContext(context.Background(), e, w, cube.Pos{0, 1, 1}, cube.Pos{6, 1, 1}, 1000, 32, 0)
+			if !errors.Is(err, pathfind.ErrUnreachable) {
+				t.Errorf("FindPathContext() across the chasm error = %v, want %v", err, pathfind.ErrUnreachable)
+			}
+
+			// With a floor below the blocks, they can be walked through.
+			w = testworld.Parse(legend, "#######\n#######\n#######", layer)
+			e = evaluator.WalkNodeEvaluatorConfig{Box: playerBox}.New()
+			if _, err := pathfind.FindPathContext(context.Background(), e, w, cube.Pos{0, 1, 1}, cube.Pos{6, 1, 1}, 1000, 32, 0); err != nil {
+				t.Errorf("FindPathContext() through the blocks error = %v, want nil", err)
+			}
+		})
+	}
+}
+
+// predecessorEvaluator is an evaluator that may be searched backwards.
+type predecessorEvaluator interface {
+	pathfind.NodeEvaluator
+	pathfind.PredecessorEvaluator
+	Node(pos cube.Pos) *pathfind.Node
+}
+
+func TestWalkNodeEvaluatorNeighbor(t *testing.T) {
+	// A pillar two blocks high to fall from, a ladder, water, a fence and a door.
+	w := testworld.New(
+		flatFloor,
+		"##H..\n..~~.\nF....\n...#.\nD....",
+		".#H..\n.....\n.....\n...#.\nD....",
+	)
+	amphibious, err := evaluator.AmphibiousNodeEvaluatorConfig{Box: playerBox}.New()
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	evaluators := map[string]predecessorEvaluator{
+		"walk":       evaluator.WalkNodeEvaluatorConfig{Box: playerBox, CanClimb: true, CanPathDoors: true, CanOpenDoors: true}.New(),
+		"amphibious": amphibious,
+	}
+	for name, e := range evaluators {
+		t.Run(name, func(t *testing.T) {
+			e.Prepare(w, cube.Pos{4, 1, 4})
+			defer e.Done()
+
+			for x := 0; x < 5; x++ {
+				for y := 1; y < 4; y++ {
+					for z := 0; z < 5; z++ {
+						from := e.Node(cube.Pos{x, y, z})
+						want := map[cube.Pos]*pathfind.Node{}
+						for _, n := range e.Neighbors(from) {
+							want[n.Pos] = n
+						}
+						// Blocked nodes are not shared, so nodes are compared by value.
+						for pos, n := range want {
+							if got := e.Neighbor(from, pos); got == nil || got.Pos != n.Pos || got.Type != n.Type || got.CostMalus != n.CostMalus {
+								t.Errorf("Neighbor(%v, %v) = %v, want %v", from.Pos, pos, got, n)
+							}
+						}
+						for dx := -1; dx <= 1; dx++ {
+							for dy := -1; dy <= 1; dy++ {
+								for dz := -1; dz <= 1; dz++ {
+									pos := from.Pos.Add(cube.Pos{dx, dy, dz})
+									if got := e.Neighbor(from, pos); want[pos] == nil && got != nil {
+										t.Errorf("Neighbor(%v, %v) = %v, want nil", from.Pos, pos, got)
+									}
+								}
+							}
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestWalkNodeEvaluatorCanMoveDirectly(t *testing.T) {
+	tests := []struct {
+		name     string
+		layers   []string
+		blocks   map[cube.Pos]world.Block
+		config   evaluator.WalkNodeEvaluatorConfig
+		from, to cube.Pos
+		want     bool
+	}{
+		{
+			name:   "open ground",
+			layers: []string{flatFloor},
+			from:   cube.Pos{0, 1, 0},
+			to:     cube.Pos{4, 1, 3},
+			want:   true,
+		},
+		{
+			name:   "different level",
+			layers: []string{flatFloor, middleRow("....#")},
+			from:   cube.Pos{0, 1, 2},
+			to:     cube.Pos{4, 2, 2},
+		},
+		{
+			name:   "drop",
+			layers: []string{flatFloor, middleRow("##.##")},
+			from:   cube.Pos{0, 2, 2},
+			to:     cube.Pos{4, 2, 2},
+		},
+		{
+			name:   "wall",
+			layers: []string{flatFloor, middleRow("..#.."), middleRow("..#..")},
+			from:   cube.Pos{0, 1, 2},
+			to:     cube.Pos{4, 1, 2},
+		},
+		{
+			// The carpet does not change the path types, but it is in the way of the entity's head.
+			name:   "carpet at head height",
+			layers: []string{flatFloor},
+			blocks: map[cube.Pos]world.Block{{2, 2, 2}: block.Carpet{}},
+			from:   cube.Pos{0, 1, 2},
+			to:     cube.Pos{4, 1, 2},
+		},
+		{
+			name:   "door",
+			layers: []string{flatFloor, doorway("D"), doorway("D")},
+			config: evaluator.WalkNodeEvaluatorConfig{CanPathDoors: true, CanOpenDoors: true},
+			from:   cube.Pos{0, 1, 2},
+			to:     cube.Pos{4, 1, 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.Box = playerBox
+			w := testworld.New(tt.layers...)
+			for pos, b := range tt.blocks {
+				w.Set(pos, b)
+			}
+			e := tt.config.New()
+			e.Prepare(w, tt.from)
+			defer e.Done()
+
+			node := func(pos cube.Pos) *pathfind.Node {
+				n := e.Node(pos)
+				n.Type = e.CachedBlockPathType(w, pos)
+				return n
+			}
+			if got := e.CanMoveDirectly(w, node(tt.from), node(tt.to)); got != tt.want {
+				t.Errorf("CanMoveDirectly() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
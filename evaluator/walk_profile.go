@@ -0,0 +1,270 @@
+package evaluator
+
+import (
+	"errors"
+	"fmt"
+	"github.com/FDUTCH/Pathfinder/path"
+	"github.com/df-mc/dragonfly/server/block"
+	"github.com/df-mc/dragonfly/server/block/cube"
+	"github.com/df-mc/dragonfly/server/world"
+	"github.com/pelletier/go-toml"
+	"io"
+	"os"
+	"slices"
+	"strings"
+)
+
+var (
+	// ErrUnknownProfileKey is returned if a profile has a key that is not a profile setting.
+	ErrUnknownProfileKey = errors.New("evaluator: unknown profile key")
+	// ErrUnknownPathType is returned if a cost is set for a path.BlockPathType name that does not exist.
+	ErrUnknownPathType = errors.New("evaluator: unknown block path type")
+	// ErrInvalidProfileValue is returned if a profile setting has a value of the wrong type or out of range.
+	ErrInvalidProfileValue = errors.New("evaluator: invalid profile value")
+)
+
+// ProfileError is returned if a profile is invalid. It points at the key that caused it.
+type ProfileError struct {
+	// Key is the full dotted key of the offending setting, starting with the profile name.
+	Key string
+	// Line and Col are the position of the key in the TOML document, or zero if unknown.
+	Line, Col int
+	// Err is the reason the setting is invalid.
+	Err error
+}
+
+// Error ...
+func (e *ProfileError) Error() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("%v: %v", e.Key, e.Err)
+	}
+	return fmt.Sprintf("%v (line %v, column %v): %v", e.Key, e.Line, e.Col, e.Err)
+}
+
+// Unwrap ...
+func (e *ProfileError) Unwrap() error {
+	return e.Err
+}
+
+// profileLiquids holds the liquids that may be listed in liquids_can_stand_on by their name.
+var profileLiquids = map[string]world.Liquid{
+	"water": block.Water{Still: true, Depth: 8},
+	"lava":  block.Lava{Still: true, Depth: 8},
+}
+
+// LoadWalkProfilesFile reads the TOML file at the path passed using LoadWalkProfiles.
+func LoadWalkProfilesFile(name string) (map[string]WalkNodeEvaluatorConfig, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return LoadWalkProfiles(f)
+}
+
+// LoadWalkProfiles reads navigation profiles from TOML and returns them by their name, usually the entity
+// type they are for. Every profile is a table with the following keys, all of which are optional:
+//
+//	[zombie]
+//	step_up = 1.0                      # WalkNodeEvaluatorConfig.MaxStepUp
+//	max_fall_distance = 3              # WalkNodeEvaluatorConfig.MaxFallDistance
+//	can_path_doors = true              # WalkNodeEvaluatorConfig.CanPathDoors
+//	can_open_doors = false             # WalkNodeEvaluatorConfig.CanOpenDoors
+//	can_break_doors = true             # WalkNodeEvaluatorConfig.CanBreakDoors
+//	can_open_gates = false             # WalkNodeEvaluatorConfig.CanOpenGates
+//	can_float = true                   # WalkNodeEvaluatorConfig.CanFloat
+//	can_walk_over_fences = false       # WalkNodeEvaluatorConfig.CanWalkOverFences
+//	can_climb = false                  # WalkNodeEvaluatorConfig.CanClimb
+//	liquids_can_stand_on = ["lava"]    # WalkNodeEvaluatorConfig.LiquidsCanStandOn, "water" or "lava"
+//	width = 0.6                        # WalkNodeEvaluatorConfig.Box, centred on the entity
+//	height = 1.95
+//
+//	[zombie.costs]                     # WalkNodeEvaluatorConfig.CostMap, keyed by path.BlockPathType name
+//	WATER = 8
+//	DANGER_FIRE = -1
+//
+// An invalid profile results in a *ProfileError pointing at the offending key.
+func LoadWalkProfiles(r io.Reader) (map[string]WalkNodeEvaluatorConfig, error) {
+	tree, err := toml.LoadReader(r)
+	if err != nil {
+		return nil, err
+	}
+	profiles := make(map[string]WalkNodeEvaluatorConfig)
+	for _, name := range sortedKeys(tree) {
+		keys := []string{name}
+		profile, ok := tree.GetPath(keys).(*toml.Tree)
+		if !ok {
+			return nil, profileError(tree, keys, fmt.Errorf("%w: profile must be a table", ErrInvalidProfileValue))
+		}
+		c, err := walkProfile(tree, profile, keys)
+		if err != nil {
+			return nil, err
+		}
+		profiles[name] = c
+	}
+	return profiles, nil
+}
+
+// walkProfile reads the WalkNodeEvaluatorConfig from the profile table at the keys passed.
+func walkProfile(root, profile *toml.Tree, keys []string) (WalkNodeEvaluatorConfig, error) {
+	var (
+		c             WalkNodeEvaluatorConfig
+		width, height float64
+		err           error
+	)
+	for _, key := range sortedKeys(profile) {
+		keys := append(slices.Clone(keys), key)
+		value := profile.GetPath([]string{key})
+		switch key {
+		case "step_up":
+			c.MaxStepUp, err = positiveFloat(value)
+		case "max_fall_distance":
+			c.MaxFallDistance, err = positiveInt(value)
+		case "can_path_doors":
+			c.CanPathDoors, err = boolValue(value)
+		case "can_open_doors":
+			c.CanOpenDoors, err = boolValue(value)
+		case "can_break_doors":
+			c.CanBreakDoors, err = boolValue(value)
+		case "can_open_gates":
+			c.CanOpenGates, err = boolValue(value)
+		case "can_float":
+			c.CanFloat, err = boolValue(value)
+		case "can_walk_over_fences":
+			c.CanWalkOverFences, err = boolValue(value)
+		case "can_climb":
+			c.CanClimb, err = boolValue(value)
+		case "liquids_can_stand_on":
+			c.LiquidsCanStandOn, err = liquids(value)
+		case "width":
+			width, err = positiveFloat(value)
+		case "height":
+			height, err = positiveFloat(value)
+		case "costs":
+			c.CostMap, err = costMap(root, value, keys)
+		default:
+			err = ErrUnknownProfileKey
+		}
+		if err != nil {
+			var profileErr *ProfileError
+			if errors.As(err, &profileErr) {
+				return c, err
+			}
+			return c, profileError(root, keys, err)
+		}
+	}
+	if c.CanBreakDoors && !c.CanPathDoors {
+		keys := append(slices.Clone(keys), "can_break_doors")
+		return c, profileError(root, keys, fmt.Errorf("%w: can_path_doors must be set too", ErrInvalidProfileValue))
+	}
+	if (width == 0) != (height == 0) {
+		// Point at the key that is set, as the missing one has no position.
+		key := "width"
+		if width == 0 {
+			key = "height"
+		}
+		keys := append(slices.Clone(keys), key)
+		return c, profileError(root, keys, fmt.Errorf("%w: width and height must be set together", ErrInvalidProfileValue))
+	}
+	if width != 0 {
+		c.Box = cube.Box(-width/2, 0, -width/2, width/2, height, width/2)
+	}
+	return c, nil
+}
+
+// costMap reads the path.CostMap from the costs table at the keys passed.
+func costMap(root *toml.Tree, value any, keys []string) (path.CostMap, error) {
+	costs, ok := value.(*toml.Tree)
+	if !ok {
+		return nil, fmt.Errorf("%w: costs must be a table", ErrInvalidProfileValue)
+	}
+	m := path.CostMap{}
+	for _, name := range sortedKeys(costs) {
+		keys := append(slices.Clone(keys), name)
+		pathType, ok := path.ByName(name)
+		if !ok {
+			return nil, profileError(root, keys, ErrUnknownPathType)
+		}
+		malus, err := floatValue(costs.GetPath([]string{name}))
+		if err == nil && malus < 0 && malus != path.BLOCKED_MALUS {
+			err = fmt.Errorf("%w: cost must be %v or at least 0", ErrInvalidProfileValue, path.BLOCKED_MALUS)
+		}
+		if err != nil {
+			return nil, profileError(root, keys, err)
+		}
+		m.SetPathfindingMalus(pathType, malus)
+	}
+	return m, nil
+}
+
+// liquids returns the liquids named in the array passed.
+func liquids(value any) ([]world.Liquid, error) {
+	names, ok := value.([]any)
+	if !ok {
+		return nil, fmt.Errorf("%w: must be an array of liquid names", ErrInvalidProfileValue)
+	}
+	l := make([]world.Liquid, 0, len(names))
+	for _, v := range names {
+		name, _ := v.(string)
+		liquid, ok := profileLiquids[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown liquid %v, must be water or lava", ErrInvalidProfileValue, v)
+		}
+		l = append(l, liquid)
+	}
+	return l, nil
+}
+
+// boolValue returns the value passed if it is a boolean.
+func boolValue(value any) (bool, error) {
+	b, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("%w: must be a boolean", ErrInvalidProfileValue)
+	}
+	return b, nil
+}
+
+// floatValue returns the value passed if it is a number.
+func floatValue(value any) (float64, error) {
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case int64:
+		return float64(v), nil
+	}
+	return 0, fmt.Errorf("%w: must be a number", ErrInvalidProfileValue)
+}
+
+// positiveFloat returns the value passed if it is a number above zero.
+func positiveFloat(value any) (float64, error) {
+	f, err := floatValue(value)
+	if err == nil && f <= 0 {
+		err = fmt.Errorf("%w: must be above 0", ErrInvalidProfileValue)
+	}
+	return f, err
+}
+
+// positiveInt returns the value passed if it is an integer above zero.
+func positiveInt(value any) (int, error) {
+	i, ok := value.(int64)
+	if !ok {
+		return 0, fmt.Errorf("%w: must be an integer", ErrInvalidProfileValue)
+	}
+	if i <= 0 {
+		return 0, fmt.Errorf("%w: must be above 0", ErrInvalidProfileValue)
+	}
+	return int(i), nil
+}
+
+// profileError returns a *ProfileError for the keys passed with the position of the key in the tree.
+func profileError(root *toml.Tree, keys []string, err error) *ProfileError {
+	pos := root.GetPositionPath(keys)
+	return &ProfileError{Key: strings.Join(keys, "."), Line: pos.Line, Col: pos.Col, Err: err}
+}
+
+// sortedKeys returns the keys of the tree passed in order, so that errors are reported deterministically.
+func sortedKeys(tree *toml.Tree) []string {
+	keys := tree.Keys()
+	slices.Sort(keys)
+	return keys
+}
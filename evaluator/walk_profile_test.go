@@ -0,0 +1,160 @@
+package evaluator_test
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/FDUTCH/Pathfinder/evaluator"
+	"github.com/FDUTCH/Pathfinder/path"
+	"github.com/df-mc/dragonfly/server/block"
+	"github.com/df-mc/dragonfly/server/block/cube"
+	"github.com/df-mc/dragonfly/server/world"
+)
+
+const profiles = `
+[zombie]
+step_up = 1
+max_fall_distance = 3
+can_path_doors = true
+can_break_doors = true
+width = 0.6
+height = 1.95
+
+[zombie.costs]
+WATER = 8
+DANGER_FIRE = -1
+
+[strider]
+liquids_can_stand_on = ["lava"]
+can_open_gates = true
+
+[strider.costs]
+LAVA = 0
+`
+
+func TestLoadWalkProfiles(t *testing.T) {
+	got, err := evaluator.LoadWalkProfiles(strings.NewReader(profiles))
+	if err != nil {
+		t.Fatalf("LoadWalkProfiles() error = %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("LoadWalkProfiles() returned %v profiles, want 2", len(got))
+	}
+
+	zombie := got["zombie"]
+	if zombie.MaxStepUp != 1 || zombie.MaxFallDistance != 3 || !zombie.CanPathDoors || !zombie.CanBreakDoors || zombie.CanOpenDoors {
+		t.Errorf("zombie = %+v, want step up 1, fall distance 3 and breaking doors", zombie)
+	}
+	if want := cube.Box(-0.3, 0, -0.3, 0.3, 1.95, 0.3); zombie.Box != want {
+		t.Errorf("zombie.Box = %v, want %v", zombie.Box, want)
+	}
+	if zombie.CostMap.PathfindingMalus(path.WATER) != 8 || zombie.CostMap.PathfindingMalus(path.DANGER_FIRE) != path.BLOCKED_MALUS {
+		t.Errorf("zombie.CostMap = %v, want WATER 8 and DANGER_FIRE blocked", zombie.CostMap)
+	}
+
+	strider := got["strider"]
+	if !strider.CanOpenGates || !slices.Equal(strider.LiquidsCanStandOn, []world.Liquid{block.Lava{Still: true, Depth: 8}}) {
+		t.Errorf("strider = %+v, want lava to stand on and opening gates", strider)
+	}
+	if strider.CostMap.PathfindingMalus(path.LAVA) != 0 {
+		t.Errorf("strider.CostMap = %v, want LAVA 0", strider.CostMap)
+	}
+}
+
+func TestLoadWalkProfilesInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		profiles string
+		wantKey  string
+		wantLine int
+		wantErr  error
+	}{
+		{
+			name:     "unknown key",
+			profiles: "[zombie]\nstep_up = 1\nstepup = 2",
+			wantKey:  "zombie.stepup",
+			wantLine: 3,
+			wantErr:  evaluator.ErrUnknownProfileKey,
+		},
+		{
+			name:     "unknown path type",
+			profiles: "[zombie.costs]\nWATER = 8\nWATR = 8",
+			wantKey:  "zombie.costs.WATR",
+			wantLine: 3,
+			wantErr:  evaluator.ErrUnknownPathType,
+		},
+		{
+			name:     "negative cost",
+			profiles: "[zombie.costs]\nWATER = -2",
+			wantKey:  "zombie.costs.WATER",
+			wantLine: 2,
+			wantErr:  evaluator.ErrInvalidProfileValue,
+		},
+		{
+			name:     "wrong type",
+			profiles: "[zombie]\ncan_climb = \"yes\"",
+			wantKey:  "zombie.can_climb",
+			wantLine: 2,
+			wantErr:  evaluator.ErrInvalidProfileValue,
+		},
+		{
+			name:     "negative step up",
+			profiles: "[zombie]\nstep_up = -1",
+			wantKey:  "zombie.step_up",
+			wantLine: 2,
+			wantErr:  evaluator.ErrInvalidProfileValue,
+		},
+		{
+			name:     "fractional fall distance",
+			profiles: "[zombie]\nmax_fall_distance = 2.5",
+			wantKey:  "zombie.max_fall_distance",
+			wantLine: 2,
+			wantErr:  evaluator.ErrInvalidProfileValue,
+		},
+		{
+			name:     "unknown liquid",
+			profiles: "[zombie]\nliquids_can_stand_on = [\"milk\"]",
+			wantKey:  "zombie.liquids_can_stand_on",
+			wantLine: 2,
+			wantErr:  evaluator.ErrInvalidProfileValue,
+		},
+		{
+			name:     "breaking doors without pathing doors",
+			profiles: "[zombie]\ncan_break_doors = true",
+			wantKey:  "zombie.can_break_doors",
+			wantLine: 2,
+			wantErr:  evaluator.ErrInvalidProfileValue,
+		},
+		{
+			name:     "width without height",
+			profiles: "[zombie]\nwidth = 0.6",
+			wantKey:  "zombie.width",
+			wantLine: 2,
+			wantErr:  evaluator.ErrInvalidProfileValue,
+		},
+		{
+			name:     "profile not a table",
+			profiles: "zombie = 1",
+			wantKey:  "zombie",
+			wantLine: 1,
+			wantErr:  evaluator.ErrInvalidProfileValue,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := evaluator.LoadWalkProfiles(strings.NewReader(tt.profiles))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("LoadWalkProfiles() error = %v, want %v", err, tt.wantErr)
+			}
+			var profileErr *evaluator.ProfileError
+			if !errors.As(err, &profileErr) {
+				t.Fatalf("LoadWalkProfiles() error = %v, want a *ProfileError", err)
+			}
+			if profileErr.Key != tt.wantKey || profileErr.Line != tt.wantLine {
+				t.Errorf("ProfileError at %v line %v, want %v line %v", profileErr.Key, profileErr.Line, tt.wantKey, tt.wantLine)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package pathfind
+
+// Heap is a slice-backed binary min-heap. The backing slice is reused when the heap is cleared, so that a heap
+// does not allocate once it has grown to the size it is used with.
+type Heap[T any] struct {
+	items    []T
+	less     func(a, b T) bool
+	setIndex func(item T, index int)
+}
+
+// NewHeap returns an empty Heap ordered by less. setIndex is called with the index of an item each time it
+// is moved, and with -1 when it is removed. It may be nil if items do not track their index.
+func NewHeap[T any](less func(a, b T) bool, setIndex func(item T, index int)) *Heap[T] {
+	if setIndex == nil {
+		setIndex = func(T, int) {}
+	}
+	return &Heap[T]{less: less, setIndex: setIndex}
+}
+
+// Push adds an item to the heap.
+func (h *Heap[T]) Push(item T) {
+	h.items = append(h.items, item)
+	h.up(len(h.items) - 1)
+}
+
+// Pop removes and returns the smallest item of the heap. The heap must not be empty.
+func (h *Heap[T]) Pop() T {
+	return h.Remove(0)
+}
+
+// Peek returns the smallest item of the heap without removing it. The heap must not be empty.
+func (h *Heap[T]) Peek() T {
+	return h.items[0]
+}
+
+// Remove removes and returns the item at the index passed.
+func (h *Heap[T]) Remove(i int) T {
+	item := h.items[i]
+	last := len(h.items) - 1
+	if i != last {
+		h.items[i] = h.items[last]
+		h.setIndex(h.items[i], i)
+	}
+	var zero T
+	h.items[last] = zero
+	h.items = h.items[:last]
+	if i != last {
+		h.Fix(i)
+	}
+	h.setIndex(item, -1)
+	return item
+}
+
+// Fix restores the order of the heap after the item at the index passed changed.
+func (h *Heap[T]) Fix(i int) {
+	if !h.down(i) {
+		h.up(i)
+	}
+}
+
+// Len returns the amount of items in the heap.
+func (h *Heap[T]) Len() int {
+	return len(h.items)
+}
+
+// Clear removes all items from the heap, keeping the backing slice for reuse.
+func (h *Heap[T]) Clear() {
+	for _, item := range h.items {
+		h.setIndex(item, -1)
+	}
+	clear(h.items)
+	h.items = h.items[:0]
+}
+
+// Items returns the items of the heap in heap order. The slice is only valid until the heap is changed.
+func (h *Heap[T]) Items() []T {
+	return h.items
+}
+
+// up moves the item at the index passed up until its parent is not larger.
+func (h *Heap[T]) up(i int) {
+	item := h.items[i]
+	for i > 0 {
+		parent := (i - 1) >> 1
+		if !h.less(item, h.items[parent]) {
+			break
+		}
+		h.items[i] = h.items[parent]
+		h.setIndex(h.items[i], i)
+		i = parent
+	}
+	h.items[i] = item
+	h.setIndex(item, i)
+}
+
+// down moves the item at the index passed down until its children are not smaller. It reports if the item
+// was moved.
+func (h *Heap[T]) down(i int) bool {
+	start, item, n := i, h.items[i], len(h.items)
+	for {
+		child := (i << 1) + 1
+		if child >= n {
+			break
+		}
+		if right := child + 1; right < n && h.less(h.items[right], h.items[child]) {
+			child = right
+		}
+		if !h.less(h.items[child], item) {
+			break
+		}
+		h.items[i] = h.items[child]
+		h.setIndex(h.items[i], i)
+		i = child
+	}
+	h.items[i] = item
+	h.setIndex(item, i)
+	return i > start
+}
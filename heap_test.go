@@ -0,0 +1,153 @@
+package pathfind_test
+
+import (
+	"slices"
+	"testing"
+
+	pathfind "github.com/FDUTCH/Pathfinder"
+)
+
+// heapItem is an item of a Heap that tracks its index.
+type heapItem struct {
+	value, index int
+}
+
+// newItemHeap returns a Heap of heapItems holding the values passed, and the items in the order of the values.
+func newItemHeap(values ...int) (*pathfind.Heap[*heapItem], []*heapItem) {
+	h := pathfind.NewHeap(func(a, b *heapItem) bool {
+		return a.value < b.value
+	}, func(item *heapItem, index int) {
+		item.index = index
+	})
+	items := make([]*heapItem, len(values))
+	for i, v := range values {
+		items[i] = &heapItem{value: v}
+		h.Push(items[i])
+	}
+	return h, items
+}
+
+// checkHeap checks that every item of the heap is not smaller than its parent and knows its index.
+func checkHeap(t *testing.T, h *pathfind.Heap[*heapItem]) {
+	t.Helper()
+	items := h.Items()
+	for i, item := range items {
+		if item.index != i {
+			t.Errorf("item %v at index %v has index %v", item.value, i, item.index)
+		}
+		if parent := (i - 1) / 2; i > 0 && item.value < items[parent].value {
+			t.Errorf("item %v at index %v is smaller than its parent %v", item.value, i, items[parent].value)
+		}
+	}
+}
+
+func TestHeap(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []int
+		// change changes the heap after the values are pushed.
+		change func(t *testing.T, h *pathfind.Heap[*heapItem], items []*heapItem)
+		// want holds the values popped from the heap after the change, in order.
+		want []int
+	}{
+		{
+			name:   "push",
+			values: []int{5, 3, 8, 1, 9, 2, 7},
+			want:   []int{1, 2, 3, 5, 7, 8, 9},
+		},
+		{
+			name:   "duplicates",
+			values: []int{2, 1, 2, 1, 2},
+			want:   []int{1, 1, 2, 2, 2},
+		},
+		{
+			name:   "remove middle",
+			values: []int{1, 2, 3, 4, 5, 6, 7},
+			change: func(t *testing.T, h *pathfind.Heap[*heapItem], items []*heapItem) {
+				if got := h.Remove(items[3].index); got != items[3] {
+					t.Errorf("Remove() = %v, want %v", got.value, items[3].value)
+				}
+			},
+			want: []int{1, 2, 3, 5, 6, 7},
+		},
+		{
+			// The last item takes the place of the removed one and must move up.
+			name:   "remove middle moving last up",
+			values: []int{1, 10, 2, 11, 12, 3, 4},
+			change: func(t *testing.T, h *pathfind.Heap[*heapItem], items []*heapItem) {
+				h.Remove(items[4].index)
+			},
+			want: []int{1, 2, 3, 4, 10, 11},
+		},
+		{
+			name:   "remove last",
+			values: []int{1, 2, 3},
+			change: func(t *testing.T, h *pathfind.Heap[*heapItem], items []*heapItem) {
+				h.Remove(h.Len() - 1)
+			},
+			want: []int{1, 2},
+		},
+		{
+			name:   "fix decreased middle",
+			values: []int{1, 2, 3, 4, 5, 6, 7},
+			change: func(t *testing.T, h *pathfind.Heap[*heapItem], items []*heapItem) {
+				items[5].value = 0
+				h.Fix(items[5].index)
+			},
+			want: []int{0, 1, 2, 3, 4, 5, 7},
+		},
+		{
+			name:   "fix increased middle",
+			values: []int{1, 2, 3, 4, 5, 6, 7},
+			change: func(t *testing.T, h *pathfind.Heap[*heapItem], items []*heapItem) {
+				items[1].value = 10
+				h.Fix(items[1].index)
+			},
+			want: []int{1, 3, 4, 5, 6, 7, 10},
+		},
+		{
+			name:   "clear",
+			values: []int{3, 1, 2},
+			change: func(t *testing.T, h *pathfind.Heap[*heapItem], items []*heapItem) {
+				h.Clear()
+				for _, item := range items {
+					if item.index != -1 {
+						t.Errorf("item %v has index %v after Clear(), want -1", item.value, item.index)
+					}
+				}
+				h.Push(&heapItem{value: 5})
+				h.Push(&heapItem{value: 4})
+			},
+			want: []int{4, 5},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, items := newItemHeap(tt.values...)
+			checkHeap(t, h)
+			if tt.change != nil {
+				tt.change(t, h, items)
+				checkHeap(t, h)
+			}
+			if h.Len() != len(tt.want) {
+				t.Errorf("Len() = %v, want %v", h.Len(), len(tt.want))
+			}
+
+			var got []int
+			for h.Len() > 0 {
+				if peek := h.Peek(); peek != h.Items()[0] {
+					t.Errorf("Peek() = %v, want the first item %v", peek.value, h.Items()[0].value)
+				}
+				item := h.Pop()
+				if item.index != -1 {
+					t.Errorf("popped item %v has index %v, want -1", item.value, item.index)
+				}
+				got = append(got, item.value)
+				checkHeap(t, h)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("popped %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
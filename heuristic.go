@@ -0,0 +1,86 @@
+package pathfind
+
+import (
+	"github.com/df-mc/dragonfly/server/block/cube"
+	"math"
+)
+
+// Heuristic estimates the cost of moving from one position to another. Heuristics that never overestimate the
+// cost find optimal paths, higher estimates make the search greedier and faster.
+type Heuristic interface {
+	// Estimate returns the estimated cost of moving from one position to another.
+	Estimate(from, to cube.Pos) float64
+}
+
+// HeuristicFunc is a function implementing Heuristic.
+type HeuristicFunc func(from, to cube.Pos) float64
+
+// Estimate ...
+func (f HeuristicFunc) Estimate(from, to cube.Pos) float64 {
+	return f(from, to)
+}
+
+var (
+	// Euclidean is the straight line distance between two positions. It is the default heuristic.
+	Euclidean = HeuristicFunc(func(from, to cube.Pos) float64 {
+		return from.Vec3().Sub(to.Vec3()).Len()
+	})
+	// Manhattan is the sum of the distances along each axis. It overestimates diagonal moves.
+	Manhattan = HeuristicFunc(func(from, to cube.Pos) float64 {
+		return float64(Abs(from.X()-to.X()) + Abs(from.Y()-to.Y()) + Abs(from.Z()-to.Z()))
+	})
+	// Octile is the exact distance when moving along axes and diagonals only.
+	Octile = HeuristicFunc(func(from, to cube.Pos) float64 {
+		d := [3]float64{
+			float64(Abs(from.X() - to.X())),
+			float64(Abs(from.Y() - to.Y())),
+			float64(Abs(from.Z() - to.Z())),
+		}
+		if d[0] < d[1] {
+			d[0], d[1] = d[1], d[0]
+		}
+		if d[1] < d[2] {
+			d[1], d[2] = d[2], d[1]
+		}
+		if d[0] < d[1] {
+			d[0], d[1] = d[1], d[0]
+		}
+		return d[0] + (math.Sqrt2-1)*d[1] + (math.Sqrt(3)-math.Sqrt2)*d[2]
+	})
+	// Chebyshev is the largest of the distances along each axis.
+	Chebyshev = HeuristicFunc(func(from, to cube.Pos) float64 {
+		return float64(max(Abs(from.X()-to.X()), Abs(from.Y()-to.Y()), Abs(from.Z()-to.Z())))
+	})
+)
+
+// weightedHeuristic is a Heuristic with its estimates multiplied by a weight.
+type weightedHeuristic struct {
+	Heuristic
+	weight float64
+}
+
+// Weighted returns the Heuristic passed with a weight that estimates are multiplied by. Weights above 1 trade
+// the optimality of paths for speed.
+func Weighted(h Heuristic, weight float64) Heuristic {
+	return weightedHeuristic{Heuristic: h, weight: weight}
+}
+
+// Estimate ...
+func (h weightedHeuristic) Estimate(from, to cube.Pos) float64 {
+	return h.Heuristic.Estimate(from, to) * h.weight
+}
+
+// defaultHeuristic is the Heuristic used by searches if none is passed.
+var defaultHeuristic = Weighted(Euclidean, FUDGING)
+
+// heuristicOf returns the first Heuristic passed or the default one, split into the heuristic and its weight.
+func heuristicOf(heuristic []Heuristic) (Heuristic, float64) {
+	h := defaultHeuristic
+	if len(heuristic) > 0 && heuristic[0] != nil {
+		h = heuristic[0]
+	}
+	if weighted, ok := h.(weightedHeuristic); ok {
+		return weighted.Heuristic, weighted.weight
+	}
+	return h, 1
+}
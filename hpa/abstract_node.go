@@ -0,0 +1,25 @@
+package hpa
+
+import (
+	"github.com/df-mc/dragonfly/server/block/cube"
+	"slices"
+)
+
+// abstractNode is a node of the abstract search.
+type abstractNode struct {
+	pos    cube.Pos
+	g, f   float64
+	parent *abstractNode
+	closed bool
+	index  int
+}
+
+// route returns the positions from the start of the search to the node.
+func (n *abstractNode) route() []cube.Pos {
+	var route []cube.Pos
+	for current := n; current != nil; current = current.parent {
+		route = append(route, current.pos)
+	}
+	slices.Reverse(route)
+	return route
+}
@@ -0,0 +1,387 @@
+// Package hpa implements hierarchical pathfinding (HPA*) on top of the A* search of package pathfind. The world
+// is partitioned into chunk aligned clusters connected by entrances on their borders. Long routes are first
+// searched on the graph of entrances and then refined segment by segment with regular A*.
+package hpa
+
+import (
+	"errors"
+	"github.com/FDUTCH/Pathfinder"
+	"github.com/df-mc/dragonfly/server/block/cube"
+	"github.com/df-mc/dragonfly/server/world"
+	"math"
+	"slices"
+)
+
+// ErrAbstractNodeBudget is returned when the abstract search visited the max amount of entrances allowed.
+var ErrAbstractNodeBudget = errors.New("hpa: max visited entrances exceeded")
+
+type Config struct {
+	// Evaluator is the evaluator used for all low-level searches, usually an evaluator.WalkNodeEvaluator.
+	Evaluator pathfind.NodeEvaluator
+	// ClusterSize is the width and length of a cluster. Defaults to 16, the size of a chunk.
+	ClusterSize int
+	// MinY and MaxY are the bounds of the heights scanned for entrances between clusters. If both are zero,
+	// the height range of world.Overworld is used.
+	MinY, MaxY int
+	// EntityHeight is the amount of passable blocks needed above the floor for a position to be an entrance.
+	// Defaults to 2.
+	EntityHeight int
+	// MaxVisitedNodes is the max amount of nodes visited by each low-level search. Defaults to 1024.
+	MaxVisitedNodes int
+	// MaxAbstractNodes is the max amount of entrances visited by the abstract search. Defaults to 4096.
+	MaxAbstractNodes int
+}
+
+func (c Config) New() *Graph {
+	if c.ClusterSize <= 0 {
+		c.ClusterSize = 16
+	}
+	if c.MinY == 0 && c.MaxY == 0 {
+		r := world.Overworld.Range()
+		c.MinY, c.MaxY = r.Min(), r.Max()
+	}
+	if c.MinY > c.MaxY {
+		c.MinY, c.MaxY = c.MaxY, c.MinY
+	}
+	if c.EntityHeight <= 0 {
+		c.EntityHeight = 2
+	}
+	if c.MaxVisitedNodes <= 0 {
+		c.MaxVisitedNodes = 1024
+	}
+	if c.MaxAbstractNodes <= 0 {
+		c.MaxAbstractNodes = 4096
+	}
+	return &Graph{
+		conf:        c,
+		clusters:    map[clusterPos]*cluster{},
+		borders:     map[border][]transition{},
+		transitions: map[cube.Pos][]edge{},
+	}
+}
+
+// Graph is the abstract graph of clusters and their entrances. Clusters are built lazily when a search
+// reaches them and are kept until they are invalidated. A Graph is not safe for concurrent use.
+type Graph struct {
+	conf Config
+
+	clusters    map[clusterPos]*cluster
+	borders     map[border][]transition
+	transitions map[cube.Pos][]edge
+}
+
+// clusterPos is the position of a cluster in cluster coordinates.
+type clusterPos [2]int
+
+// border is the border between a cluster and its neighbour in the positive direction of an axis.
+type border struct {
+	cluster clusterPos
+	// axis is 0 for the X axis and 1 for the Z axis.
+	axis int
+}
+
+// transition is a pair of entrances connecting two clusters.
+type transition struct {
+	from, to cube.Pos
+}
+
+// edge is an edge of the abstract graph.
+type edge struct {
+	to   cube.Pos
+	cost float64
+}
+
+// cluster holds the entrances of a cluster and the costs of moving between them.
+type cluster struct {
+	entrances []cube.Pos
+	edges     map[cube.Pos][]edge
+}
+
+// FindPath searches a path from start to target. The abstract graph is searched first and the route found is
+// refined with regular A* searches between consecutive entrances. If the target cannot be reached, the
+// refined part of the route is returned with an error.
+func (g *Graph) FindPath(source world.BlockSource, start, target cube.Pos) (*pathfind.Path, error) {
+	startCluster, targetCluster := g.clusterPosOf(start), g.clusterPosOf(target)
+	if startCluster == targetCluster {
+		if p := g.findPath(source, start, target); p.Reached() {
+			return p, nil
+		}
+	}
+
+	startEdges := g.connect(source, start, g.cluster(source, startCluster).entrances, false)
+	targetEdges := map[cube.Pos]float64{}
+	for _, e := range g.connect(source, target, g.cluster(source, targetCluster).entrances, true) {
+		targetEdges[e.to] = e.cost
+	}
+
+	route, err := g.abstractSearch(source, start, target, startEdges, targetEdges)
+	if err != nil {
+		return pathfind.NewPath(nil, false, target), err
+	}
+	return g.refine(source, route, target)
+}
+
+// Invalidate removes the cluster containing pos and its neighbours from the graph, so that they are built again
+// the next time they are needed. It should be called when a block changes.
+func (g *Graph) Invalidate(pos cube.Pos) {
+	c := g.clusterPosOf(pos)
+	for _, b := range []border{{c, 0}, {c, 1}, {clusterPos{c[0] - 1, c[1]}, 0}, {clusterPos{c[0], c[1] - 1}, 1}} {
+		for _, t := range g.borders[b] {
+			g.removeTransition(t.from, t.to)
+			g.removeTransition(t.to, t.from)
+		}
+		delete(g.borders, b)
+	}
+	for _, neighbor := range []clusterPos{c, {c[0] - 1, c[1]}, {c[0] + 1, c[1]}, {c[0], c[1] - 1}, {c[0], c[1] + 1}} {
+		delete(g.clusters, neighbor)
+	}
+}
+
+// removeTransition removes the edge from one entrance to another over a border. Entrances at the corners of
+// clusters may have edges over other borders too, which are kept.
+func (g *Graph) removeTransition(from, to cube.Pos) {
+	edges := slices.DeleteFunc(g.transitions[from], func(e edge) bool {
+		return e.to == to
+	})
+	if len(edges) == 0 {
+		delete(g.transitions, from)
+		return
+	}
+	g.transitions[from] = edges
+}
+
+// Clear removes all clusters from the graph.
+func (g *Graph) Clear() {
+	clear(g.clusters)
+	clear(g.borders)
+	clear(g.transitions)
+}
+
+// abstractSearch runs A* on the graph of entrances and returns the positions along the route found, including
+// start and target.
+func (g *Graph) abstractSearch(source world.BlockSource, start, target cube.Pos, startEdges []edge, targetEdges map[cube.Pos]float64) ([]cube.Pos, error) {
+	open := pathfind.NewHeap(func(a, b *abstractNode) bool {
+		return a.f < b.f
+	}, func(node *abstractNode, index int) {
+		node.index = index
+	})
+	nodes := map[cube.Pos]*abstractNode{}
+	estimate := func(pos cube.Pos) float64 {
+		return pathfind.Euclidean.Estimate(pos, target)
+	}
+
+	nodes[start] = &abstractNode{pos: start, f: estimate(start)}
+	open.Push(nodes[start])
+
+	for visited := 0; open.Len() > 0; visited++ {
+		if visited >= g.conf.MaxAbstractNodes {
+			return nil, ErrAbstractNodeBudget
+		}
+		current := open.Pop()
+		current.closed = true
+		if current.pos == target {
+			return current.route(), nil
+		}
+
+		var edges []edge
+		if current.pos == start {
+			edges = startEdges
+		} else {
+			edges = g.edges(source, current.pos)
+			if cost, ok := targetEdges[current.pos]; ok {
+				edges = append(slices.Clip(edges), edge{to: target, cost: cost})
+			}
+		}
+		for _, e := range edges {
+			cost := current.g + e.cost
+			neighbor, ok := nodes[e.to]
+			if !ok {
+				neighbor = &abstractNode{pos: e.to, index: -1}
+				nodes[e.to] = neighbor
+			} else if neighbor.closed || cost >= neighbor.g {
+				continue
+			}
+			neighbor.parent, neighbor.g, neighbor.f = current, cost, cost+estimate(e.to)
+			if neighbor.index < 0 {
+				open.Push(neighbor)
+			} else {
+				open.Fix(neighbor.index)
+			}
+		}
+	}
+	return nil, pathfind.ErrUnreachable
+}
+
+// refine builds the path along the route passed using regular A* searches between consecutive positions.
+func (g *Graph) refine(source world.BlockSource, route []cube.Pos, target cube.Pos) (*pathfind.Path, error) {
+	var nodes []*pathfind.Node
+	for i := 1; i < len(route); i++ {
+		segment := g.findPath(source, route[i-1], route[i])
+		for j := range segment.Count() {
+			nodes = append(nodes, segment.Node(j))
+		}
+		if !segment.Reached() {
+			return pathfind.NewPath(nodes, false, target), pathfind.ErrUnreachable
+		}
+	}
+	return pathfind.NewPath(nodes, true, target), nil
+}
+
+// edges returns the abstract edges leaving the entrance at pos.
+func (g *Graph) edges(source world.BlockSource, pos cube.Pos) []edge {
+	c := g.cluster(source, g.clusterPosOf(pos))
+	return append(slices.Clip(c.edges[pos]), g.transitions[pos]...)
+}
+
+// connect returns edges between pos and each of the entrances passed that can be reached with a low-level
+// search. If reverse is true, the searches run from the entrances to pos.
+func (g *Graph) connect(source world.BlockSource, pos cube.Pos, entrances []cube.Pos, reverse bool) []edge {
+	var edges []edge
+	for _, entrance := range entrances {
+		from, to := pos, entrance
+		if reverse {
+			from, to = entrance, pos
+		}
+		if cost, ok := g.cost(source, from, to); ok {
+			edges = append(edges, edge{to: entrance, cost: cost})
+		}
+	}
+	return edges
+}
+
+// cluster returns the cluster at the position passed, building it if needed.
+func (g *Graph) cluster(source world.BlockSource, pos clusterPos) *cluster {
+	if c, ok := g.clusters[pos]; ok {
+		return c
+	}
+	c := &cluster{edges: map[cube.Pos][]edge{}}
+	for _, b := range []border{{pos, 0}, {pos, 1}, {clusterPos{pos[0] - 1, pos[1]}, 0}, {clusterPos{pos[0], pos[1] - 1}, 1}} {
+		for _, t := range g.border(source, b) {
+			if g.clusterPosOf(t.from) == pos {
+				c.entrances = append(c.entrances, t.from)
+			} else {
+				c.entrances = append(c.entrances, t.to)
+			}
+		}
+	}
+	for i, from := range c.entrances {
+		for _, to := range c.entrances[i+1:] {
+			if cost, ok := g.cost(source, from, to); ok {
+				c.edges[from] = append(c.edges[from], edge{to: to, cost: cost})
+			}
+			if cost, ok := g.cost(source, to, from); ok {
+				c.edges[to] = append(c.edges[to], edge{to: from, cost: cost})
+			}
+		}
+	}
+	g.clusters[pos] = c
+	return c
+}
+
+// border returns the transitions over the border passed, computing them if needed. Each run of adjacent
+// positions on the border that can be crossed at the same height results in a transition at its middle.
+func (g *Graph) border(source world.BlockSource, b border) []transition {
+	if transitions, ok := g.borders[b]; ok {
+		return transitions
+	}
+	size := g.conf.ClusterSize
+	across, along := cube.Pos{1, 0, 0}, cube.Pos{0, 0, 1}
+	if b.axis == 1 {
+		across, along = along, across
+	}
+	origin := cube.Pos{b.cluster[0]*size + across.X()*(size-1), 0, b.cluster[1]*size + across.Z()*(size-1)}
+
+	var (
+		transitions []transition
+		run         []transition
+	)
+	flush := func() {
+		if len(run) > 0 {
+			transitions = append(transitions, run[len(run)/2])
+			run = run[:0]
+		}
+	}
+	for y := g.conf.MinY; y <= g.conf.MaxY; y++ {
+		for i := range size {
+			from := origin.Add(cube.Pos{along.X() * i, 0, along.Z() * i}).Add(cube.Pos{0, y, 0})
+			t, ok := g.crossing(source, from, across)
+			if !ok || (len(run) > 0 && t.to.Y() != run[len(run)-1].to.Y()) {
+				flush()
+			}
+			if ok {
+				run = append(run, t)
+			}
+		}
+		flush()
+	}
+
+	for _, t := range transitions {
+		cost := t.from.Vec3().Sub(t.to.Vec3()).Len()
+		g.transitions[t.from] = append(g.transitions[t.from], edge{to: t.to, cost: cost})
+		g.transitions[t.to] = append(g.transitions[t.to], edge{to: t.from, cost: cost})
+	}
+	g.borders[b] = transitions
+	return transitions
+}
+
+// crossing returns the transition from the position passed to the adjacent position in the direction of across,
+// allowing a height difference of one block.
+func (g *Graph) crossing(source world.BlockSource, from, across cube.Pos) (transition, bool) {
+	if !g.standable(source, from) {
+		return transition{}, false
+	}
+	for _, dy := range []int{0, 1, -1} {
+		to := from.Add(across).Add(cube.Pos{0, dy, 0})
+		if g.standable(source, to) {
+			return transition{from: from, to: to}, true
+		}
+	}
+	return transition{}, false
+}
+
+// standable checks if an entity can stand at the position passed.
+func (g *Graph) standable(source world.BlockSource, pos cube.Pos) bool {
+	below := pos.Side(cube.FaceDown)
+	if pathfind.ComputationTypeLand.Pathfindable(source.Block(below), source, below) {
+		return false
+	}
+	for i := range g.conf.EntityHeight {
+		p := pos.Add(cube.Pos{0, i, 0})
+		if !pathfind.ComputationTypeLand.Pathfindable(source.Block(p), source, p) {
+			return false
+		}
+	}
+	return true
+}
+
+// cost returns the cost of the path from one position to another found by a low-level search.
+func (g *Graph) cost(source world.BlockSource, from, to cube.Pos) (float64, bool) {
+	p := g.findPath(source, from, to)
+	if !p.Reached() {
+		return 0, false
+	}
+	cost, previous := 0.0, from.Vec3()
+	for i := range p.Count() {
+		node := p.Node(i)
+		cost += node.Vec3().Sub(previous).Len() + max(node.CostMalus, 0)
+		previous = node.Vec3()
+	}
+	return cost, true
+}
+
+// findPath runs a low-level search from one position to another.
+func (g *Graph) findPath(source world.BlockSource, from, to cube.Pos) *pathfind.Path {
+	maxDistance := from.Vec3().Sub(to.Vec3()).Len() + float64(g.conf.ClusterSize)
+	return pathfind.FindPath(g.conf.Evaluator, source, from, to, g.conf.MaxVisitedNodes, maxDistance, 0)
+}
+
+// clusterPosOf returns the position of the cluster containing pos.
+func (g *Graph) clusterPosOf(pos cube.Pos) clusterPos {
+	size := g.conf.ClusterSize
+	return clusterPos{floorDiv(pos.X(), size), floorDiv(pos.Z(), size)}
+}
+
+// floorDiv divides a by b rounding towards negative infinity.
+func floorDiv(a, b int) int {
+	return int(math.Floor(float64(a) / float64(b)))
+}
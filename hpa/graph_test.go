@@ -0,0 +1,126 @@
+package hpa_test
+
+import (
+	"errors"
+	"testing"
+
+	pathfind "github.com/FDUTCH/Pathfinder"
+	"github.com/FDUTCH/Pathfinder/evaluator"
+	"github.com/FDUTCH/Pathfinder/hpa"
+	"github.com/FDUTCH/Pathfinder/internal/testworld"
+	"github.com/df-mc/dragonfly/server/block"
+	"github.com/df-mc/dragonfly/server/block/cube"
+)
+
+// newGraph returns a Graph with clusters of the size passed using a WalkNodeEvaluator for a player sized entity.
+func newGraph(clusterSize int) *hpa.Graph {
+	return hpa.Config{
+		Evaluator:   evaluator.WalkNodeEvaluatorConfig{Box: cube.Box(-0.3, 0, -0.3, 0.3, 1.8, 0.3)}.New(),
+		ClusterSize: clusterSize,
+	}.New()
+}
+
+// checkPath fails the test if the path passed does not lead from start to target through adjacent positions.
+func checkPath(t *testing.T, p *pathfind.Path, start, target cube.Pos) {
+	t.Helper()
+	if !p.Reached() || p.EndNode().Pos != target {
+		t.Fatalf("path does not reach %v", target)
+	}
+	previous := start
+	for i := 0; i < p.Count(); i++ {
+		pos := p.Node(i).Pos
+		if d := pos.Sub(previous); max(pathfind.Abs(d.X()), pathfind.Abs(d.Z())) > 1 || pathfind.Abs(d.Y()) > 1 {
+			t.Fatalf("node %v at %v is not next to %v", i, pos, previous)
+		}
+		previous = pos
+	}
+}
+
+// plains returns a stone floor of the size passed at y=0.
+func plains(size int) *testworld.World {
+	w := testworld.New()
+	w.Fill(cube.Pos{0, 0, 0}, cube.Pos{size - 1, 0, size - 1}, block.Stone{})
+	return w
+}
+
+func TestGraphFindPath(t *testing.T) {
+	w := plains(32)
+	// A wall across the world at x=12 with a gap at z=28, so that the path has to leave the straight line.
+	w.Fill(cube.Pos{12, 1, 0}, cube.Pos{12, 2, 27}, block.Stone{})
+	g := newGraph(8)
+
+	start, target := cube.Pos{2, 1, 2}, cube.Pos{29, 1, 3}
+	p, err := g.FindPath(w, start, target)
+	if err != nil {
+		t.Fatalf("FindPath() error = %v", err)
+	}
+	checkPath(t, p, start, target)
+	for i := 0; i < p.Count(); i++ {
+		if pos := p.Node(i).Pos; pos.X() == 12 && pos.Z() != 28 {
+			t.Fatalf("node %v at %v passes the wall", i, pos)
+		}
+	}
+
+	// Within a single cluster, the path is searched directly.
+	p, err = g.FindPath(w, cube.Pos{1, 1, 1}, cube.Pos{6, 1, 6})
+	if err != nil {
+		t.Fatalf("FindPath() within a cluster error = %v", err)
+	}
+	checkPath(t, p, cube.Pos{1, 1, 1}, cube.Pos{6, 1, 6})
+}
+
+func TestGraphFindPathUnreachable(t *testing.T) {
+	w := plains(24)
+	w.Fill(cube.Pos{12, 1, 0}, cube.Pos{12, 2, 23}, block.Stone{})
+	if _, err := newGraph(8).FindPath(w, cube.Pos{2, 1, 2}, cube.Pos{20, 1, 3}); !errors.Is(err, pathfind.ErrUnreachable) {
+		t.Fatalf("FindPath() error = %v, want %v", err, pathfind.ErrUnreachable)
+	}
+}
+
+func TestGraphInvalidate(t *testing.T) {
+	w := plains(24)
+	w.Fill(cube.Pos{12, 1, 0}, cube.Pos{12, 2, 23}, block.Stone{})
+	w.Fill(cube.Pos{12, 1, 4}, cube.Pos{12, 2, 4}, block.Air{})
+	g := newGraph(8)
+
+	start, target := cube.Pos{2, 1, 2}, cube.Pos{20, 1, 3}
+	if _, err := g.FindPath(w, start, target); err != nil {
+		t.Fatalf("FindPath() through gap error = %v", err)
+	}
+
+	// Moving the gap is only noticed once the clusters around it are invalidated.
+	w.Fill(cube.Pos{12, 1, 4}, cube.Pos{12, 2, 4}, block.Stone{})
+	w.Fill(cube.Pos{12, 1, 20}, cube.Pos{12, 2, 20}, block.Air{})
+	g.Invalidate(cube.Pos{12, 1, 4})
+	g.Invalidate(cube.Pos{12, 1, 20})
+	p, err := g.FindPath(w, start, target)
+	if err != nil {
+		t.Fatalf("FindPath() through moved gap error = %v", err)
+	}
+	checkPath(t, p, start, target)
+
+	w.Fill(cube.Pos{12, 1, 20}, cube.Pos{12, 2, 20}, block.Stone{})
+	g.Invalidate(cube.Pos{12, 1, 20})
+	if _, err := g.FindPath(w, start, target); !errors.Is(err, pathfind.ErrUnreachable) {
+		t.Fatalf("FindPath() after closing gap error = %v, want %v", err, pathfind.ErrUnreachable)
+	}
+}
+
+func TestGraphInvalidateCorner(t *testing.T) {
+	// An L shaped walkway that crosses from cluster (0, 0) to (1, 0) and from there to (1, 1) at the same
+	// position, (4, 1, 3), which is an entrance of both borders.
+	w := testworld.New("........\n........\n........\n#####...\n....#...\n....#...\n....#...\n....#...")
+	g := newGraph(4)
+
+	start, target := cube.Pos{0, 1, 3}, cube.Pos{4, 1, 7}
+	if _, err := g.FindPath(w, start, target); err != nil {
+		t.Fatalf("FindPath() error = %v", err)
+	}
+	// Invalidating cluster (0, 0) rebuilds the border to (1, 0), but not the one between (1, 0) and (1, 1).
+	g.Invalidate(cube.Pos{0, 1, 0})
+	p, err := g.FindPath(w, start, target)
+	if err != nil {
+		t.Fatalf("FindPath() after Invalidate() error = %v", err)
+	}
+	checkPath(t, p, start, target)
+}
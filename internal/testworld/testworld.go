@@ -0,0 +1,106 @@
+// Package testworld provides an in-memory world.BlockSource built from ASCII maps for tests.
+package testworld
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/df-mc/dragonfly/server/block"
+	"github.com/df-mc/dragonfly/server/block/cube"
+	"github.com/df-mc/dragonfly/server/world"
+)
+
+// Legend maps the characters of an ASCII map to blocks.
+type Legend map[rune]world.Block
+
+// DefaultLegend is the Legend used by New.
+var DefaultLegend = Legend{
+	'.': block.Air{},
+	'#': block.Stone{},
+	'~': block.Water{Still: true, Depth: 8},
+	'L': block.Lava{Still: true, Depth: 8},
+	'D': block.WoodDoor{},
+	'd': block.WoodDoor{Open: true},
+	'I': block.CopperDoor{},
+	'F': block.WoodFence{},
+	'W': block.Wall{Block: block.Cobblestone{}},
+	'G': block.WoodFenceGate{},
+	'g': block.WoodFenceGate{Open: true},
+	'T': block.WoodTrapdoor{},
+	'H': block.Ladder{Facing: cube.North},
+	'C': block.Cactus{},
+	'c': block.CocoaBean{},
+	'f': block.Fire{},
+	'l': block.Leaves{},
+}
+
+// World is an in-memory world.BlockSource. Positions without a block hold air.
+type World struct {
+	blocks map[cube.Pos]world.Block
+}
+
+// New returns a World built from ASCII layers using the DefaultLegend. See Parse.
+func New(layers ...string) *World {
+	return Parse(DefaultLegend, layers...)
+}
+
+// Parse returns a World built from ASCII layers using the legend passed. The first layer is at y=0 and each
+// following layer is one block higher. Lines of a layer are rows along the z axis and characters are columns
+// along the x axis, both starting at 0. Surrounding whitespace is ignored. Doors placed above doors become
+// their top half. Parse panics if a character is not in the legend.
+func Parse(legend Legend, layers ...string) *World {
+	w := &World{blocks: map[cube.Pos]world.Block{}}
+	for y, layer := range layers {
+		for z, row := range strings.Split(strings.TrimSpace(layer), "\n") {
+			for x, char := range strings.TrimSpace(row) {
+				b, ok := legend[char]
+				if !ok {
+					panic(fmt.Sprintf("testworld: character %q at %v is not in the legend", char, cube.Pos{x, y, z}))
+				}
+				w.Set(cube.Pos{x, y, z}, b)
+			}
+		}
+	}
+	return w
+}
+
+// Set places a block at the position passed. Doors placed above doors become their top half.
+func (w *World) Set(pos cube.Pos, b world.Block) {
+	below := w.Block(pos.Side(cube.FaceDown))
+	switch door := b.(type) {
+	case block.WoodDoor:
+		if _, ok := below.(block.WoodDoor); ok {
+			door.Top = true
+			b = door
+		}
+	case block.CopperDoor:
+		if _, ok := below.(block.CopperDoor); ok {
+			door.Top = true
+			b = door
+		}
+	}
+	if _, ok := b.(block.Air); ok {
+		delete(w.blocks, pos)
+		return
+	}
+	w.blocks[pos] = b
+}
+
+// Fill places the block passed in the box between the two corners passed, both included.
+func (w *World) Fill(from, to cube.Pos, b world.Block) {
+	for x := min(from.X(), to.X()); x <= max(from.X(), to.X()); x++ {
+		for y := min(from.Y(), to.Y()); y <= max(from.Y(), to.Y()); y++ {
+			for z := min(from.Z(), to.Z()); z <= max(from.Z(), to.Z()); z++ {
+				w.Set(cube.Pos{x, y, z}, b)
+			}
+		}
+	}
+}
+
+// Block ...
+func (w *World) Block(pos cube.Pos) world.Block {
+	if b, ok := w.blocks[pos]; ok {
+		return b
+	}
+	return block.Air{}
+}
@@ -0,0 +1,349 @@
+package pathfind
+
+import (
+	"github.com/df-mc/dragonfly/server/block/cube"
+	"github.com/df-mc/dragonfly/server/world"
+)
+
+// DefaultMaxJump is the max length of a single jump used when none is passed to NewJumpPointEvaluator.
+const DefaultMaxJump = 32
+
+// FlatGround may be implemented by a NodeEvaluator so that a JumpPointEvaluator can cheaply check if
+// positions are flat ground, instead of expanding all their neighbors.
+type FlatGround interface {
+	// FlatNode returns the node at pos and the level of its floor if the entity can walk there without a
+	// cost malus. If it returns nil, obstacle reports if the entity cannot enter pos in any way, not even by
+	// stepping up or falling, so that it may be treated as a wall.
+	FlatNode(pos cube.Pos) (node *Node, floor float64, obstacle bool)
+}
+
+// JumpPointEvaluator wraps a NodeEvaluator to search using Jump Point Search. On flat layers of uniform cost
+// it skips the nodes that optimal paths could pass in several symmetric ways, so that far fewer nodes are
+// visited. Nodes next to height changes, obstacles or path types with a cost malus are expanded with all
+// neighbors of the wrapped evaluator. Paths found skip the straight runs between jump points, like smoothed
+// paths do. Evaluators that do not implement FlatGround are always expanded with all neighbors.
+type JumpPointEvaluator struct {
+	NodeEvaluator
+	flat    FlatGround
+	maxJump int
+	targets []cube.Pos
+	nodes   []*Node
+	// cells holds the positions checked in the current search. They only depend on the world, not on the
+	// state of the search.
+	cells map[cube.Pos]*cell
+	// runs holds the straight runs checked in the current search, which are reused by the diagonal jumps
+	// crossing them.
+	runs map[runKey]run
+	line []cube.Pos
+}
+
+// runKey is a position and a straight direction.
+type runKey struct {
+	pos    cube.Pos
+	dx, dz int
+}
+
+// run is the straight line of nodes from a position in a direction up to a jump point or an obstacle.
+type run struct {
+	// steps is the amount of nodes in the line, including the first one and the jump point.
+	steps int
+	// jumpPoint is true if the line ends with a jump point, false if it ends before an obstacle.
+	jumpPoint bool
+	// complete is false if the line was cut off after the max jump length without reaching its end.
+	complete bool
+}
+
+// cell is a position checked using FlatGround.
+type cell struct {
+	// node, floor and obstacle are the results of FlatGround.FlatNode.
+	node     *Node
+	floor    float64
+	obstacle bool
+	// layer is the layer of the position if checked is true.
+	layer   layer
+	checked bool
+}
+
+// NewJumpPointEvaluator returns a JumpPointEvaluator wrapping the evaluator passed. Jumps longer than maxJump
+// are split, DefaultMaxJump is used if maxJump is not positive.
+func NewJumpPointEvaluator(evaluator NodeEvaluator, maxJump int) *JumpPointEvaluator {
+	if maxJump <= 0 {
+		maxJump = DefaultMaxJump
+	}
+	flat, _ := evaluator.(FlatGround)
+	return &JumpPointEvaluator{NodeEvaluator: evaluator, flat: flat, maxJump: maxJump, cells: map[cube.Pos]*cell{}, runs: map[runKey]run{}}
+}
+
+// Prepare ...
+func (e *JumpPointEvaluator) Prepare(source world.BlockSource, pos cube.Pos) {
+	e.targets = e.targets[:0]
+	clear(e.cells)
+	clear(e.runs)
+	e.NodeEvaluator.Prepare(source, pos)
+}
+
+// UpdateSource ...
+func (e *JumpPointEvaluator) UpdateSource(source world.BlockSource) {
+	if updater, ok := e.NodeEvaluator.(SourceUpdater); ok {
+		updater.UpdateSource(source)
+	}
+}
+
+// Goal ...
+func (e *JumpPointEvaluator) Goal(pos cube.Pos) *Target {
+	e.targets = append(e.targets, pos)
+	return e.NodeEvaluator.Goal(pos)
+}
+
+// Neighbors returns the jump points reachable from the node, or all neighbors of the wrapped evaluator if the
+// node is not on a flat layer of uniform cost.
+func (e *JumpPointEvaluator) Neighbors(node *Node) []*Node {
+	l, ok := e.layerOf(node.Pos)
+	if !ok {
+		return e.NodeEvaluator.Neighbors(node)
+	}
+	e.nodes = e.nodes[:0]
+	for _, d := range e.directions(node, l) {
+		if jumpPoint := e.jump(l.at(d[0], d[1]), d[0], d[1]); jumpPoint != nil && !jumpPoint.Closed {
+			e.nodes = append(e.nodes, jumpPoint)
+		}
+	}
+	return e.nodes
+}
+
+// directions returns the directions in which jumps are made from the node. All directions are used if the
+// node was not reached on the same level, otherwise the symmetric ones are pruned.
+func (e *JumpPointEvaluator) directions(node *Node, l layer) [][2]int {
+	parent := node.cameFrom
+	if parent == nil || parent.Y() != node.Y() {
+		var directions [][2]int
+		for dx := -1; dx <= 1; dx++ {
+			for dz := -1; dz <= 1; dz++ {
+				if (dx != 0 || dz != 0) && l.walkable(dx, dz) {
+					directions = append(directions, [2]int{dx, dz})
+				}
+			}
+		}
+		return directions
+	}
+
+	dx, dz := sign(node.X()-parent.X()), sign(node.Z()-parent.Z())
+	var directions [][2]int
+	add := func(dx, dz int) {
+		if l.walkable(dx, dz) {
+			directions = append(directions, [2]int{dx, dz})
+		}
+	}
+	switch {
+	case dx != 0 && dz != 0:
+		add(0, dz)
+		add(dx, 0)
+		add(dx, dz)
+	case dx != 0:
+		add(dx, 0)
+		add(0, 1)
+		add(0, -1)
+		add(dx, 1)
+		add(dx, -1)
+	default:
+		add(0, dz)
+		add(1, 0)
+		add(-1, 0)
+		add(1, dz)
+		add(-1, dz)
+	}
+	return directions
+}
+
+// jump moves from the node in the direction passed until a jump point is found. It returns nil if the moves
+// lead nowhere of interest.
+func (e *JumpPointEvaluator) jump(node *Node, dx, dz int) *Node {
+	if dx == 0 || dz == 0 {
+		return e.jumpStraight(node, dx, dz)
+	}
+	for steps := 1; node != nil; steps++ {
+		if steps >= e.maxJump || e.turning(node.Pos, dx, dz) {
+			return node
+		}
+		l, ok := e.layerOf(node.Pos)
+		if !ok {
+			return node
+		}
+		if e.jumpStraight(l.at(dx, 0), dx, 0) != nil || e.jumpStraight(l.at(0, dz), 0, dz) != nil {
+			return node
+		}
+		node = l.at(dx, dz)
+	}
+	return nil
+}
+
+// jumpStraight moves from the node in the straight direction passed like jump.
+func (e *JumpPointEvaluator) jumpStraight(node *Node, dx, dz int) *Node {
+	if node == nil {
+		return nil
+	}
+	r := e.runOf(node.Pos, dx, dz)
+	steps := r.steps
+	if steps >= e.maxJump {
+		steps = e.maxJump
+	} else if !r.jumpPoint {
+		return nil
+	}
+	return e.cellAt(node.Pos.Add(cube.Pos{dx * (steps - 1), 0, dz * (steps - 1)})).node
+}
+
+// runOf returns the run from the position in the straight direction passed, checking the positions on it
+// that are not part of a run checked before.
+func (e *JumpPointEvaluator) runOf(pos cube.Pos, dx, dz int) run {
+	e.line = e.line[:0]
+	var r run
+	for {
+		if cached, ok := e.runs[runKey{pos, dx, dz}]; ok && (cached.complete || len(e.line)+cached.steps >= e.maxJump) {
+			r = cached
+			break
+		}
+		if len(e.line) == e.maxJump {
+			break
+		}
+		e.line = append(e.line, pos)
+		if e.straightJumpPoint(pos, dx, dz) {
+			r = run{jumpPoint: true, complete: true}
+			break
+		}
+		l, _ := e.layerOf(pos)
+		next := l.at(dx, dz)
+		if next == nil {
+			r = run{complete: true}
+			break
+		}
+		pos = next.Pos
+	}
+	for i := len(e.line) - 1; i >= 0; i-- {
+		r.steps++
+		e.runs[runKey{e.line[i], dx, dz}] = r
+	}
+	return r
+}
+
+// straightJumpPoint reports if the position is a jump point when moving in the straight direction passed.
+func (e *JumpPointEvaluator) straightJumpPoint(pos cube.Pos, dx, dz int) bool {
+	if e.turning(pos, dx, dz) {
+		return true
+	}
+	l, ok := e.layerOf(pos)
+	if !ok {
+		return true
+	}
+	if dx != 0 {
+		return l.walkable(0, 1) && !l.walkable(-dx, 1) || l.walkable(0, -1) && !l.walkable(-dx, -1)
+	}
+	return l.walkable(1, 0) && !l.walkable(1, -dz) || l.walkable(-1, 0) && !l.walkable(-1, -dz)
+}
+
+// turning reports if no target is closer to the position passed in the direction passed than in any other.
+// Optimal paths to the targets change direction there, so jumps end at the position. Positions further on are
+// only checked if the search gets to them, which keeps the paths found optimal, as jumps may end at any
+// position, like they do with the max jump length.
+func (e *JumpPointEvaluator) turning(pos cube.Pos, dx, dz int) bool {
+	for _, target := range e.targets {
+		x, z := (target.X()-pos.X())*dx, (target.Z()-pos.Z())*dz
+		switch {
+		case dx != 0 && dz != 0:
+			if x > 0 && z > 0 {
+				return false
+			}
+		case dx != 0:
+			if x > Abs(target.Z()-pos.Z()) {
+				return false
+			}
+		default:
+			if z > Abs(target.X()-pos.X()) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// layer holds the neighbors of a node on flat ground that can be walked to. Neighbors that cannot be walked
+// to are obstacles.
+type layer struct {
+	nodes   [3][3]*Node
+	uniform bool
+}
+
+// at returns the neighbor in the direction passed, or nil if it cannot be walked to.
+func (l layer) at(dx, dz int) *Node {
+	return l.nodes[dx+1][dz+1]
+}
+
+// walkable reports if the neighbor in the direction passed can be walked to.
+func (l layer) walkable(dx, dz int) bool {
+	return l.at(dx, dz) != nil
+}
+
+// layerOf returns the layer of the position passed. It reports false if the position is not flat ground or
+// has a neighbor that is neither flat ground on the same level nor an obstacle, in which case the position
+// must be expanded with all neighbors of the wrapped evaluator.
+func (e *JumpPointEvaluator) layerOf(pos cube.Pos) (layer, bool) {
+	c := e.cellAt(pos)
+	if !c.checked {
+		c.layer, c.checked = e.computeLayer(pos, c), true
+	}
+	return c.layer, c.layer.uniform
+}
+
+// computeLayer checks the neighbors of the cell at the position passed using FlatGround.
+func (e *JumpPointEvaluator) computeLayer(pos cube.Pos, c *cell) layer {
+	var l layer
+	if c.node == nil {
+		return l
+	}
+	for dx := -1; dx <= 1; dx++ {
+		for dz := -1; dz <= 1; dz++ {
+			if dx == 0 && dz == 0 {
+				continue
+			}
+			neighbor := e.cellAt(pos.Add(cube.Pos{dx, 0, dz}))
+			if neighbor.node == nil && !neighbor.obstacle || neighbor.node != nil && neighbor.floor != c.floor {
+				return layer{}
+			}
+			l.nodes[dx+1][dz+1] = neighbor.node
+		}
+	}
+	// Diagonal neighbors cannot be walked to past the corner of an obstacle.
+	for _, dx := range [2]int{-1, 1} {
+		for _, dz := range [2]int{-1, 1} {
+			if !l.walkable(dx, 0) || !l.walkable(0, dz) {
+				l.nodes[dx+1][dz+1] = nil
+			}
+		}
+	}
+	l.uniform = true
+	return l
+}
+
+// cellAt returns the cell at the position passed, checking it using FlatGround if it was not checked before.
+// Positions are part of the layers of all their neighbors, so most are checked several times.
+func (e *JumpPointEvaluator) cellAt(pos cube.Pos) *cell {
+	c, ok := e.cells[pos]
+	if !ok {
+		c = &cell{}
+		if e.flat != nil {
+			c.node, c.floor, c.obstacle = e.flat.FlatNode(pos)
+		}
+		e.cells[pos] = c
+	}
+	return c
+}
+
+// sign returns -1, 0 or 1 depending on the sign of n.
+func sign(n int) int {
+	switch {
+	case n > 0:
+		return 1
+	case n < 0:
+		return -1
+	}
+	return 0
+}
@@ -0,0 +1,104 @@
+package pathfind_test
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	pathfind "github.com/FDUTCH/Pathfinder"
+	"github.com/FDUTCH/Pathfinder/evaluator"
+	"github.com/FDUTCH/Pathfinder/internal/testworld"
+	"github.com/df-mc/dragonfly/server/block"
+	"github.com/df-mc/dragonfly/server/block/cube"
+)
+
+// walledPlains returns flat plains of the size passed enclosed by walls, with a pillar every few blocks.
+func walledPlains(size int) *testworld.World {
+	w := testworld.New()
+	w.Fill(cube.Pos{-1, -1, -1}, cube.Pos{size, -1, size}, block.Stone{})
+	w.Fill(cube.Pos{-1, 0, -1}, cube.Pos{size, 1, -1}, block.Stone{})
+	w.Fill(cube.Pos{-1, 0, size}, cube.Pos{size, 1, size}, block.Stone{})
+	w.Fill(cube.Pos{-1, 0, -1}, cube.Pos{-1, 1, size}, block.Stone{})
+	w.Fill(cube.Pos{size, 0, -1}, cube.Pos{size, 1, size}, block.Stone{})
+	for x := 6; x < size-6; x += 12 {
+		for z := 6; z < size-6; z += 12 {
+			w.Fill(cube.Pos{x, 0, z}, cube.Pos{x, 1, z}, block.Stone{})
+		}
+	}
+	return w
+}
+
+// pathLength returns the length of the path passed from the start passed, following straight lines between
+// its nodes.
+func pathLength(start cube.Pos, p *pathfind.Path) float64 {
+	var length float64
+	last := start.Vec3()
+	for i := 0; i < p.Count(); i++ {
+		length += p.NodePos(i).Sub(last).Len()
+		last = p.NodePos(i)
+	}
+	return length
+}
+
+func TestJumpPointEvaluatorOptimal(t *testing.T) {
+	source := walledPlains(64)
+	for _, target := range []cube.Pos{{60, 0, 1}, {60, 0, 60}, {60, 0, 25}, {1, 0, 60}, {30, 0, 41}} {
+		var lengths [2]float64
+		for i, jumpPoints := range []bool{false, true} {
+			var e pathfind.NodeEvaluator = evaluator.WalkNodeEvaluatorConfig{Box: cube.Box(-0.3, 0, -0.3, 0.3, 1.8, 0.3)}.New()
+			if jumpPoints {
+				e = pathfind.NewJumpPointEvaluator(e, 0)
+			}
+			result, err := pathfind.FindPathContext(context.Background(), e, source, cube.Pos{1, 0, 1}, target, 20000, 256, 0, pathfind.Euclidean)
+			if err != nil {
+				t.Fatalf("FindPathContext() to %v error = %v", target, err)
+			}
+			lengths[i] = pathLength(cube.Pos{1, 0, 1}, result.Path)
+		}
+		if math.Abs(lengths[0]-lengths[1]) > 1e-9 {
+			t.Errorf("path to %v with jump points has length %v, want %v", target, lengths[1], lengths[0])
+		}
+	}
+}
+
+func benchmarkPlains(b *testing.B, jumpPoints bool, target cube.Pos) {
+	source := walledPlains(64)
+	var visited int
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var e pathfind.NodeEvaluator = evaluator.WalkNodeEvaluatorConfig{Box: cube.Box(-0.3, 0, -0.3, 0.3, 1.8, 0.3)}.New()
+		if jumpPoints {
+			e = pathfind.NewJumpPointEvaluator(e, 0)
+		}
+		result, err := pathfind.FindPathContext(context.Background(), e, source, cube.Pos{1, 0, 1}, target, 20000, 256, 0, pathfind.Euclidean)
+		if err != nil {
+			b.Fatal(err)
+		}
+		visited += result.VisitedNodes
+	}
+	b.ReportMetric(float64(visited)/float64(b.N), "nodes/op")
+}
+
+func BenchmarkPlainsAStarStraight(b *testing.B) {
+	benchmarkPlains(b, false, cube.Pos{60, 0, 1})
+}
+
+func BenchmarkPlainsJumpPointStraight(b *testing.B) {
+	benchmarkPlains(b, true, cube.Pos{60, 0, 1})
+}
+
+func BenchmarkPlainsAStarDiagonal(b *testing.B) {
+	benchmarkPlains(b, false, cube.Pos{60, 0, 60})
+}
+
+func BenchmarkPlainsJumpPointDiagonal(b *testing.B) {
+	benchmarkPlains(b, true, cube.Pos{60, 0, 60})
+}
+
+func BenchmarkPlainsAStarOffset(b *testing.B) {
+	benchmarkPlains(b, false, cube.Pos{60, 0, 25})
+}
+
+func BenchmarkPlainsJumpPointOffset(b *testing.B) {
+	benchmarkPlains(b, true, cube.Pos{60, 0, 25})
+}
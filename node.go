@@ -16,8 +16,24 @@ type Node struct {
 	walkedDistance float64
 	CostMalus      float64
 	Type           path.BlockPathType
+	// Action is the action the entity has to take before it can enter the node.
+	Action NodeAction
 }
 
+// NodeAction is an action an entity has to take before it can enter a Node.
+type NodeAction byte
+
+const (
+	// ActionNone means the node can be entered without taking any action.
+	ActionNone NodeAction = iota
+	// ActionOpenDoor means the wooden door at the node has to be opened.
+	ActionOpenDoor
+	// ActionBreakDoor means the wooden door at the node has to be broken.
+	ActionBreakDoor
+	// ActionOpenGate means the fence gate at the node has to be opened.
+	ActionOpenGate
+)
+
 // NewNode ...
 func NewNode(pos cube.Pos) *Node {
 	return &Node{Pos: pos, heapIdx: -1}
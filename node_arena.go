@@ -0,0 +1,30 @@
+package pathfind
+
+import "github.com/df-mc/dragonfly/server/block/cube"
+
+// nodeArenaChunkSize is the amount of nodes allocated at once by a NodeArena.
+const nodeArenaChunkSize = 256
+
+// NodeArena allocates nodes in chunks that are reused once the arena is reset, so that evaluators reused for
+// many searches stop allocating nodes. The zero value is ready for use.
+type NodeArena struct {
+	chunks [][]Node
+	used   int
+}
+
+// New returns a node at the position passed, like NewNode. The node is only valid until the arena is reset.
+func (a *NodeArena) New(pos cube.Pos) *Node {
+	chunk := a.used / nodeArenaChunkSize
+	if chunk == len(a.chunks) {
+		a.chunks = append(a.chunks, make([]Node, nodeArenaChunkSize))
+	}
+	node := &a.chunks[chunk][a.used%nodeArenaChunkSize]
+	*node = Node{Pos: pos, heapIdx: -1}
+	a.used++
+	return node
+}
+
+// Reset makes all nodes of the arena available again.
+func (a *NodeArena) Reset() {
+	a.used = 0
+}
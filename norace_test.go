@@ -0,0 +1,6 @@
+//go:build !race
+
+package pathfind_test
+
+// raceEnabled is true if the tests are built with the race detector, which allocates on its own.
+const raceEnabled = false
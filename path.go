@@ -5,8 +5,32 @@ import (
 	"github.com/df-mc/dragonfly/server/world"
 	"github.com/go-gl/mathgl/mgl64"
 	"math"
+	"slices"
 )
 
+// LineChecker is implemented by evaluators that can check if an entity can move between two nodes in a
+// straight line.
+type LineChecker interface {
+	// CanMoveDirectly checks if the entity can move in a straight line from one node to another.
+	CanMoveDirectly(source world.BlockSource, from, to *Node) bool
+}
+
+// AnyAngleEvaluator is implemented by evaluators that let searches connect a node to any earlier node in a
+// straight line from it instead of the node it was reached from (Theta*), so that paths are shorter and
+// straighter than with post-hoc smoothing.
+type AnyAngleEvaluator interface {
+	LineChecker
+	// AnyAngle reports if searches should look for any-angle paths.
+	AnyAngle() bool
+}
+
+// NodeValidator is implemented by evaluators that can check if the nodes of an existing path can still be
+// passed after the world changed.
+type NodeValidator interface {
+	// ValidNode checks if the node can still be passed in the source passed.
+	ValidNode(source world.BlockSource, node *Node) bool
+}
+
 type Path struct {
 	nodes         []*Node
 	nextNodeIndex int
@@ -16,13 +40,19 @@ type Path struct {
 }
 
 func NewPath(nodes []*Node, reached bool, target cube.Pos) *Path {
+	p := makePath(nodes, reached, target)
+	return &p
+}
+
+// makePath returns a Path like NewPath without allocating it.
+func makePath(nodes []*Node, reached bool, target cube.Pos) Path {
 	distance := float64(0)
 	if len(nodes) == 0 {
 		distance = math.Inf(1)
 	} else {
 		distance = float64(nodes[len(nodes)-1].distanceManhattan(target))
 	}
-	return &Path{nodes: nodes, reached: reached, target: target, distToTarget: distance}
+	return Path{nodes: nodes, reached: reached, target: target, distToTarget: distance}
 }
 
 func (p *Path) Advance() {
@@ -64,6 +94,84 @@ func (p *Path) ReplaceNode(i int, node *Node) {
 	p.nodes[i] = node
 }
 
+// Smooth removes the nodes that can be skipped by moving in a straight line from an earlier node, so that
+// entities do not zig-zag along the grid. Nodes before the next node are kept.
+func (p *Path) Smooth(source world.BlockSource, checker LineChecker) {
+	if p.nextNodeIndex >= len(p.nodes)-2 {
+		return
+	}
+	anchor := p.nextNodeIndex
+	nodes := slices.Clone(p.nodes[:anchor+1])
+
+	for anchor < len(p.nodes)-1 {
+		next := anchor + 1
+		for next+1 < len(p.nodes) && checker.CanMoveDirectly(source, p.nodes[anchor], p.nodes[next+1]) {
+			next++
+		}
+		nodes = append(nodes, p.nodes[next])
+		anchor = next
+	}
+	p.nodes = nodes
+}
+
+// FirstInvalidNode returns the index of the first node from the next node on that can no longer be passed,
+// or -1 if all of them are still valid.
+func (p *Path) FirstInvalidNode(source world.BlockSource, validator NodeValidator) int {
+	return p.firstInvalidNode(source, validator, p.nextNodeIndex)
+}
+
+// firstInvalidNode returns the index of the first node from the index passed on that can no longer be passed,
+// or -1 if all of them are still valid.
+func (p *Path) firstInvalidNode(source world.BlockSource, validator NodeValidator, start int) int {
+	for i := start; i < len(p.nodes); i++ {
+		if !validator.ValidNode(source, p.nodes[i]) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Repath repairs the path if any of the remaining nodes can no longer be passed. Every run of invalid nodes
+// is replaced by a detour to the first valid node after it, starting at pos for the first run and at the node
+// before the run for the others. If a run has no valid node after it or its detour cannot be found, the
+// remaining nodes are replaced by a new path to the target. Repath reports if the path is valid afterwards,
+// which is the case if the target is reached or was not reached by the original path either.
+func (p *Path) Repath(evaluator NodeEvaluator, validator NodeValidator, source world.BlockSource, pos cube.Pos, maxVisitedNodes int, maxDistanceFromStart float64, reachRange int, heuristic ...Heuristic) bool {
+	check := p.nextNodeIndex
+	for first := true; ; first = false {
+		invalid := p.firstInvalidNode(source, validator, check)
+		if invalid < 0 {
+			return true
+		}
+		from, splice := pos, p.nextNodeIndex
+		if !first {
+			from, splice = p.nodes[invalid-1].Pos, invalid
+		}
+		rejoin := invalid + 1
+		for rejoin < len(p.nodes) && !validator.ValidNode(source, p.nodes[rejoin]) {
+			rejoin++
+		}
+		if rejoin == len(p.nodes) {
+			break
+		}
+		detour := FindPath(evaluator, source, from, p.nodes[rejoin].Pos, maxVisitedNodes, maxDistanceFromStart, 0, heuristic...)
+		if !detour.Reached() {
+			break
+		}
+		nodes := slices.Clone(p.nodes[:splice])
+		nodes = append(nodes, detour.nodes...)
+		check = len(nodes)
+		p.nodes = append(nodes, p.nodes[rejoin+1:]...)
+	}
+
+	reached := p.reached
+	replacement := FindPath(evaluator, source, pos, p.target, maxVisitedNodes, maxDistanceFromStart, reachRange, heuristic...)
+	p.nodes = append(p.nodes[:p.nextNodeIndex:p.nextNodeIndex], replacement.nodes...)
+	p.reached = replacement.reached
+	p.distToTarget = replacement.distToTarget
+	return p.reached || !reached
+}
+
 func (p *Path) Count() int {
 	return len(p.nodes)
 }
@@ -79,10 +187,11 @@ func (p *Path) SetNextNodeIndex(i int) {
 func (p *Path) EntityPosAtNode(ent world.Entity, i int) mgl64.Vec3 {
 	node := p.nodes[i]
 	width := ent.H().Type().BBox(ent).Width()
+	offset := math.Floor(width+1) * 0.5
 
-	x := float64(node.X()) + ((width + 1) * 0.5)
+	x := float64(node.X()) + offset
 	y := float64(node.Y())
-	z := float64(node.Z()) + ((width + 1) * 0.5)
+	z := float64(node.Z()) + offset
 	return mgl64.Vec3{x, y, z}
 }
 
@@ -0,0 +1,92 @@
+package path
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	// ErrPathTypeNameTaken is returned by Register if a path type with the name passed already exists.
+	ErrPathTypeNameTaken = errors.New("path: block path type name is already taken")
+	// ErrTooManyPathTypes is returned by Register if no more path types can be registered.
+	ErrTooManyPathTypes = errors.New("path: too many block path types registered")
+)
+
+// customType holds the properties of a path type registered using Register.
+type customType struct {
+	name             string
+	malus            int
+	partialCollision bool
+}
+
+var (
+	customMu sync.Mutex
+	// customTypes holds the registered path types in the order they were registered. The slice is never
+	// changed once it is stored, registering replaces it with an updated copy so that lookups need no locking.
+	customTypes atomic.Pointer[[]customType]
+)
+
+// Register registers a new path type with the name, default malus and partial collision passed and returns
+// it. The malus is used by CostMap if it has no malus set for the path type, BLOCKED_MALUS makes entities
+// avoid it. Path types should be registered when the program starts, as the values returned depend on the
+// order path types are registered in.
+func Register(name string, malus int, partialCollision bool) (BlockPathType, error) {
+	customMu.Lock()
+	defer customMu.Unlock()
+
+	if _, ok := ByName(name); ok {
+		return 0, ErrPathTypeNameTaken
+	}
+	var types []customType
+	if current := customTypes.Load(); current != nil {
+		types = slices.Clone(*current)
+	}
+	if len(builtinNames)+len(types) > math.MaxUint8 {
+		return 0, ErrTooManyPathTypes
+	}
+	types = append(types, customType{name: name, malus: malus, partialCollision: partialCollision})
+	customTypes.Store(&types)
+	return BlockPathType(len(builtinNames) + len(types) - 1), nil
+}
+
+// ByName returns the path type with the name passed, which may be built-in or registered using Register.
+func ByName(name string) (BlockPathType, bool) {
+	for _, t := range Types() {
+		if t.String() == name {
+			return t, true
+		}
+	}
+	return 0, false
+}
+
+// Types returns all built-in and registered path types.
+func Types() []BlockPathType {
+	n := len(builtinNames)
+	if current := customTypes.Load(); current != nil {
+		n += len(*current)
+	}
+	types := make([]BlockPathType, n)
+	for i := range types {
+		types[i] = BlockPathType(i)
+	}
+	return types
+}
+
+// Registered reports if the path type was registered using Register instead of being built-in.
+func (t BlockPathType) Registered() bool {
+	_, ok := customPathType(t)
+	return ok
+}
+
+// customPathType returns the properties of a path type registered using Register.
+func customPathType(t BlockPathType) (customType, bool) {
+	current := customTypes.Load()
+	i := int(t) - len(builtinNames)
+	if current == nil || i < 0 || i >= len(*current) {
+		return customType{}, false
+	}
+	return (*current)[i], true
+}
@@ -0,0 +1,69 @@
+package path_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/FDUTCH/Pathfinder/path"
+)
+
+func TestRegister(t *testing.T) {
+	claimed, err := path.Register("CLAIMED_LAND", 12, false)
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	boundary, err := path.Register("ARENA_BOUNDARY", path.BLOCKED_MALUS, true)
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if claimed <= path.SLOW || boundary != claimed+1 {
+		t.Fatalf("Register() = %v, %v, want consecutive values after %v", claimed, boundary, path.SLOW)
+	}
+	if _, err := path.Register("CLAIMED_LAND", 0, false); !errors.Is(err, path.ErrPathTypeNameTaken) {
+		t.Errorf("Register() with taken name error = %v, want %v", err, path.ErrPathTypeNameTaken)
+	}
+	if _, err := path.Register("WATER", 0, false); !errors.Is(err, path.ErrPathTypeNameTaken) {
+		t.Errorf("Register() with built-in name error = %v, want %v", err, path.ErrPathTypeNameTaken)
+	}
+
+	if got := claimed.String(); got != "CLAIMED_LAND" {
+		t.Errorf("String() = %v, want CLAIMED_LAND", got)
+	}
+	if got, ok := path.ByName("ARENA_BOUNDARY"); !ok || got != boundary {
+		t.Errorf("ByName() = %v, %v, want %v, true", got, ok, boundary)
+	}
+	if !slices.Contains(path.Types(), boundary) {
+		t.Errorf("Types() does not contain %v", boundary)
+	}
+	if !claimed.Registered() || path.SLOW.Registered() {
+		t.Errorf("Registered() = %v, %v, want true, false", claimed.Registered(), path.SLOW.Registered())
+	}
+	if claimed.PartialCollision() || !boundary.PartialCollision() {
+		t.Errorf("PartialCollision() = %v, %v, want false, true", claimed.PartialCollision(), boundary.PartialCollision())
+	}
+
+	costMap := path.CostMap{}
+	if got := costMap.PathfindingMalus(claimed); got != 12 {
+		t.Errorf("PathfindingMalus() = %v, want 12", got)
+	}
+	costMap.SetPathfindingMalus(claimed, 2)
+	if got := costMap.PathfindingMalus(claimed); got != 2 {
+		t.Errorf("PathfindingMalus() after setting = %v, want 2", got)
+	}
+	if got := costMap.PathfindingMalus(path.BlockPathType(255)); got != path.BLOCKED_MALUS {
+		t.Errorf("PathfindingMalus() of unknown path type = %v, want %v", got, path.BLOCKED_MALUS)
+	}
+}
+
+func TestBlockPathTypeString(t *testing.T) {
+	for _, pathType := range []path.BlockPathType{path.BLOCKED, path.WATER, path.SLOW} {
+		got, ok := path.ByName(pathType.String())
+		if !ok || got != pathType {
+			t.Errorf("ByName(%q) = %v, %v, want %v, true", pathType.String(), got, ok, pathType)
+		}
+	}
+	if got := path.BlockPathType(255).String(); got != "BlockPathType(255)" {
+		t.Errorf("String() of unknown path type = %v, want BlockPathType(255)", got)
+	}
+}
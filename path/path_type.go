@@ -1,5 +1,7 @@
 package path
 
+import "fmt"
+
 // BlockPathType represents the type of the path.
 type BlockPathType byte
 
@@ -32,12 +34,87 @@ const (
 	LEAVES
 	STICKY_HONEY
 	COCOA
+	CLIMBABLE
+	// FENCE_GATE_CLOSED is a closed fence gate. Evaluators treat it as WALKABLE_GATE if the entity can open
+	// gates, and as FENCE otherwise.
+	FENCE_GATE_CLOSED
+	// WALKABLE_GATE is a closed fence gate the entity can open.
+	WALKABLE_GATE
+	// PRESSURE_PLATE is a pressure plate, which is triggered by entities walking over it.
+	PRESSURE_PLATE
+	// TRIPWIRE is a tripwire, which is triggered by entities walking through it.
+	TRIPWIRE
+	// DAMAGE_CAUTIOUS is a block that hurts entities touching it, but is not costly to pass by default, such
+	// as wither roses and pointed dripstone.
+	DAMAGE_CAUTIOUS
+	// SLOW is a position where entities move slower, such as in cobwebs or on soul sand.
+	SLOW
 )
 
+// Malus returns the default malus of the path type. Unknown path types are blocked.
 func (t BlockPathType) Malus() int {
 	return malus(t)
 }
 
+// PartialCollision reports if blocks of the path type do not fill the whole block space, such as fences and
+// doors, so that evaluators check if entities collide with them.
+func (t BlockPathType) PartialCollision() bool {
+	switch t {
+	case FENCE, DOOR_WOOD_CLOSED, DOOR_IRON_CLOSED:
+		return true
+	}
+	if c, ok := customPathType(t); ok {
+		return c.partialCollision
+	}
+	return false
+}
+
+// String returns the name of the path type, which is the name of its constant for built-in path types.
+func (t BlockPathType) String() string {
+	if int(t) < len(builtinNames) {
+		return builtinNames[t]
+	}
+	if c, ok := customPathType(t); ok {
+		return c.name
+	}
+	return fmt.Sprintf("BlockPathType(%d)", byte(t))
+}
+
+// builtinNames holds the names of the built-in path types.
+var builtinNames = [...]string{
+	BLOCKED:            "BLOCKED",
+	OPEN:               "OPEN",
+	WALKABLE:           "WALKABLE",
+	WALKABLE_DOOR:      "WALKABLE_DOOR",
+	TRAPDOOR:           "TRAPDOOR",
+	POWDER_SNOW:        "POWDER_SNOW",
+	DANGER_POWDER_SNOW: "DANGER_POWDER_SNOW",
+	FENCE:              "FENCE",
+	LAVA:               "LAVA",
+	WATER:              "WATER",
+	WATER_BORDER:       "WATER_BORDER",
+	RAIL:               "RAIL",
+	UNPASSABLE_RAIL:    "UNPASSABLE_RAIL",
+	DANGER_FIRE:        "DANGER_FIRE",
+	DAMAGE_FIRE:        "DAMAGE_FIRE",
+	DANGER_OTHER:       "DANGER_OTHER",
+	DAMAGE_OTHER:       "DAMAGE_OTHER",
+	DOOR_OPEN:          "DOOR_OPEN",
+	DOOR_WOOD_CLOSED:   "DOOR_WOOD_CLOSED",
+	DOOR_IRON_CLOSED:   "DOOR_IRON_CLOSED",
+	BREACH:             "BREACH",
+	LEAVES:             "LEAVES",
+	STICKY_HONEY:       "STICKY_HONEY",
+	COCOA:              "COCOA",
+	CLIMBABLE:          "CLIMBABLE",
+	FENCE_GATE_CLOSED:  "FENCE_GATE_CLOSED",
+	WALKABLE_GATE:      "WALKABLE_GATE",
+	PRESSURE_PLATE:     "PRESSURE_PLATE",
+	TRIPWIRE:           "TRIPWIRE",
+	DAMAGE_CAUTIOUS:    "DAMAGE_CAUTIOUS",
+	SLOW:               "SLOW",
+}
+
 func malus(pathType BlockPathType) int {
 	switch pathType {
 	case BLOCKED:
@@ -88,7 +165,24 @@ func malus(pathType BlockPathType) int {
 		return 8
 	case COCOA:
 		return OPEN_MALUS
+	case CLIMBABLE:
+		return OPEN_MALUS
+	case FENCE_GATE_CLOSED:
+		return BLOCKED_MALUS
+	case WALKABLE_GATE:
+		return OPEN_MALUS
+	case PRESSURE_PLATE:
+		return OPEN_MALUS
+	case TRIPWIRE:
+		return OPEN_MALUS
+	case DAMAGE_CAUTIOUS:
+		return OPEN_MALUS
+	case SLOW:
+		return 8
 	default:
-		panic("should not happen")
+		if c, ok := customPathType(pathType); ok {
+			return c.malus
+		}
+		return BLOCKED_MALUS
 	}
 }
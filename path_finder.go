@@ -1,86 +1,64 @@
 package pathfind
 
 import (
+	"context"
+	"fmt"
 	"github.com/df-mc/dragonfly/server/block/cube"
 	"github.com/df-mc/dragonfly/server/world"
 	"math"
 	"slices"
+	"time"
 )
 
 const (
+	// FUDGING is the weight of the default heuristic.
 	FUDGING = 1.5
 )
 
-// FindPath builds a pathfind.Path from passed args.
-func FindPath(evaluator NodeEvaluator, source world.BlockSource, pos, target cube.Pos, maxVisitedNodes int, maxDistanceFromStart float64, reachRange int) *Path {
-	evaluator.Prepare(source, pos)
-
-	startNode := evaluator.StartNode()
-
-	actualTarget := evaluator.Goal(target)
-
-	result := findPath(evaluator, startNode, actualTarget, maxVisitedNodes, maxDistanceFromStart, reachRange)
-
-	evaluator.Done()
-
-	return result
+// FindPath builds a pathfind.Path from passed args. An optional Heuristic may be passed, by default the
+// Euclidean distance weighted by FUDGING is used.
+func FindPath(evaluator NodeEvaluator, source world.BlockSource, pos, target cube.Pos, maxVisitedNodes int, maxDistanceFromStart float64, reachRange int, heuristic ...Heuristic) *Path {
+	result, _ := FindPathContext(context.Background(), evaluator, source, pos, target, maxVisitedNodes, maxDistanceFromStart, reachRange, heuristic...)
+	return result.Path
 }
 
-// findPath finds from startNode to target.
-func findPath(evaluator NodeEvaluator, startNode *Node, target *Target, maxVisitedNodes int, maxDistanceFromStart float64, reachRange int) *Path {
-	openSet := NewBinaryHeap()
-	startNode.g = 0
-	startNode.h = bestHeuristic(startNode, target)
-	startNode.f = startNode.h
-	openSet.Insert(startNode)
-
-	visitedNodes := 0
-
-	maxDistanceFromStartSqr := math.Pow(maxDistanceFromStart, 2)
-
-	for !openSet.IsEmpty() {
-		visitedNodes++
-		if visitedNodes >= maxVisitedNodes {
-			break
-		}
-
-		current := openSet.Pop()
-		current.Closed = true
-
-		if current.distanceManhattan(target.Pos) <= reachRange {
-			target.SetReached(true)
-			break
-		}
-		if current.distanceSquared(startNode) < maxDistanceFromStartSqr {
-			for _, neighbor := range evaluator.Neighbors(current) {
-				distance := current.distance(neighbor)
-				neighbor.walkedDistance = current.walkedDistance + distance
+// FindPathContext builds a pathfind.Path from passed args. The search is aborted when ctx is cancelled.
+// The Result returned always holds the best path found, which is partial if the target was not reached.
+// The error returned matches the Result.Reason and is nil if the target was reached.
+func FindPathContext(ctx context.Context, evaluator NodeEvaluator, source world.BlockSource, pos, target cube.Pos, maxVisitedNodes int, maxDistanceFromStart float64, reachRange int, heuristic ...Heuristic) (Result, error) {
+	return FindPathMultiContext(ctx, evaluator, source, pos, []cube.Pos{target}, maxVisitedNodes, maxDistanceFromStart, reachRange, heuristic...)
+}
 
-				newNeighborG := current.g + distance + neighbor.CostMalus
-				if neighbor.walkedDistance < maxDistanceFromStart && (!neighbor.OpenSet() || newNeighborG < neighbor.g) {
-					neighbor.cameFrom = current
-					neighbor.g = newNeighborG
-					neighbor.h = bestHeuristic(neighbor, target) * FUDGING
+// FindPathMulti builds a pathfind.Path to the nearest reachable of the targets passed using a single search.
+// The Target returned is the one the Path leads to, it is nil if no targets were passed.
+func FindPathMulti(evaluator NodeEvaluator, source world.BlockSource, pos cube.Pos, targets []cube.Pos, maxVisitedNodes int, maxDistanceFromStart float64, reachRange int, heuristic ...Heuristic) (*Path, *Target) {
+	result, _ := FindPathMultiContext(context.Background(), evaluator, source, pos, targets, maxVisitedNodes, maxDistanceFromStart, reachRange, heuristic...)
+	return result.Path, result.Target
+}
 
-					if neighbor.OpenSet() {
-						openSet.ChangeCost(neighbor, neighbor.g+neighbor.h)
-					} else {
-						neighbor.f = neighbor.g + neighbor.h
-						openSet.Insert(neighbor)
-					}
-				}
+// FindPathMultiContext is the same as FindPathMulti, but it aborts the search when ctx is cancelled and
+// reports the outcome like FindPathContext.
+func FindPathMultiContext(ctx context.Context, evaluator NodeEvaluator, source world.BlockSource, pos cube.Pos, targets []cube.Pos, maxVisitedNodes int, maxDistanceFromStart float64, reachRange int, heuristic ...Heuristic) (Result, error) {
+	start := time.Now()
+	search, err := NewSearch(evaluator, source, pos, targets, maxVisitedNodes, maxDistanceFromStart, reachRange, heuristic...)
+	if err != nil {
+		return Result{}, err
+	}
+	search.step(ctx.Done(), 0, time.Time{})
 
-			}
-		}
+	result := search.result
+	result.Elapsed = time.Since(start)
+	if result.Reason == TerminationCancelled {
+		return result, fmt.Errorf("%w: %w", ErrCancelled, ctx.Err())
 	}
-	return reconstructPath(target.BestNode(), target.Pos, target.Reached())
+	return result, result.Reason.Err()
 }
 
 // bestHeuristic returns best heuristics.
-func bestHeuristic(node *Node, targets ...*Target) float64 {
+func bestHeuristic(node *Node, heuristic Heuristic, targets ...*Target) float64 {
 	bestH := math.Inf(1)
 	for _, target := range targets {
-		h := node.Vec3().Sub(target.Vec3()).Len()
+		h := heuristic.Estimate(node.Pos, target.Pos)
 		target.UpdateBest(h, node)
 		if h < bestH {
 			bestH = h
@@ -89,16 +67,36 @@ func bestHeuristic(node *Node, targets ...*Target) float64 {
 	return bestH
 }
 
-// reconstructPath...
-func reconstructPath(startNode *Node, target cube.Pos, reached bool) *Path {
-	var nodes []*Node
-	currentNode := startNode
-	for currentNode.cameFrom != nil {
-		nodes = append(nodes, currentNode)
-		currentNode = currentNode.cameFrom
+// pathLength returns the amount of nodes of the path ending with the node passed.
+func pathLength(end *Node) int {
+	count := 0
+	for current := end; current.cameFrom != nil; current = current.cameFrom {
+		count++
+	}
+	return count
+}
+
+// appendPath appends the nodes of the path ending with the node passed to nodes, excluding the start node.
+func appendPath(nodes []*Node, end *Node) []*Node {
+	start := len(nodes)
+	for current := end; current.cameFrom != nil; current = current.cameFrom {
+		nodes = append(nodes, current)
+	}
+	slices.Reverse(nodes[start:])
+	return nodes
+}
+
+// detachNodes returns copies of the nodes passed, so that they stay valid once the evaluator that created them
+// reuses its memory for another search.
+func detachNodes(nodes []*Node) []*Node {
+	copies := make([]Node, len(nodes))
+	detached := make([]*Node, len(nodes))
+	for i, node := range nodes {
+		copies[i] = *node
+		copies[i].cameFrom, copies[i].heapIdx = nil, -1
+		detached[i] = &copies[i]
 	}
-	slices.Reverse(nodes)
-	return NewPath(nodes, reached, target)
+	return detached
 }
 
 // NodeEvaluator interface that can be used to construct path using pathfind.FindPath.
@@ -111,6 +109,7 @@ type NodeEvaluator interface {
 	StartNode() *Node
 	// Goal returns target.
 	Goal(pos cube.Pos) *Target
-	// Neighbors ...
+	// Neighbors returns the nodes that can be moved to from the node. The slice returned may be reused by
+	// the next call.
 	Neighbors(node *Node) []*Node
 }
@@ -0,0 +1,268 @@
+package pathfind_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	pathfind "github.com/FDUTCH/Pathfinder"
+	"github.com/FDUTCH/Pathfinder/evaluator"
+	"github.com/FDUTCH/Pathfinder/internal/testworld"
+	"github.com/df-mc/dragonfly/server/block"
+	"github.com/df-mc/dragonfly/server/block/cube"
+)
+
+const floor = `
+#######
+#######
+#######
+#######
+#######
+#######
+#######`
+
+const open = `
+.......
+.......
+.......
+.......
+.......
+.......
+.......`
+
+const wallWithGap = `
+...#...
+...#...
+...#...
+...#...
+...#...
+...#...
+.......`
+
+const enclosure = `
+.......
+.......
+.......
+.......
+....###
+....#.#
+....###`
+
+const doorway = `
+...#...
+...#...
+...#...
+...D...
+...#...
+...#...
+...#...`
+
+const stairs = `
+.......
+.......
+.......
+..#####
+.......
+.......
+.......`
+
+const stairsTop = `
+.......
+.......
+.......
+...####
+.......
+.......
+.......`
+
+// wideStairs is a staircase as wide as the world, so that it cannot be walked around.
+var wideStairs = []string{
+	"###########\n###########\n###########",
+	"....#######\n....#######\n....#######",
+	".....######\n.....######\n.....######",
+	"......#####\n......#####\n......#####",
+	"...........\n...........\n...........",
+	"...........\n...........\n...........",
+}
+
+// wideStairsUnderCeiling is wideStairs with a ceiling too low to jump up the first step from.
+var wideStairsUnderCeiling = []string{
+	wideStairs[0], wideStairs[1], wideStairs[2],
+	"...#..#####\n...#..#####\n...#..#####",
+	wideStairs[4], wideStairs[5],
+}
+
+const groundLadder = `
+...H...
+#######
+#######
+#######
+#######
+#######
+#######`
+
+const corridor = `
+.......
+#######
+#######
+#######
+#######
+#######
+#######`
+
+func TestFindPath(t *testing.T) {
+	tests := []struct {
+		name   string
+		layers []string
+		// start is the position the path starts at, {0, 1, 0} if zero.
+		start           cube.Pos
+		config          evaluator.WalkNodeEvaluatorConfig
+		target          cube.Pos
+		maxVisitedNodes int
+		maxDistance     float64
+		reachRange      int
+		cancelled       bool
+		wantErr         error
+		// wantCount is the amount of nodes the path should have, without the start. It is not checked if zero.
+		wantCount int
+	}{
+		{
+			name:      "straight",
+			layers:    []string{floor, open, open},
+			target:    cube.Pos{6, 1, 0},
+			wantCount: 6,
+		},
+		{
+			name:   "around wall",
+			layers: []string{floor, wallWithGap, wallWithGap},
+			target: cube.Pos{6, 1, 0},
+		},
+		{
+			name:   "up stairs",
+			layers: []string{floor, stairs, stairsTop},
+			target: cube.Pos{6, 3, 3},
+		},
+		{
+			name:   "up wide stairs",
+			layers: wideStairs,
+			start:  cube.Pos{1, 1, 1},
+			target: cube.Pos{8, 4, 1},
+		},
+		{
+			name:    "up wide stairs under ceiling",
+			layers:  wideStairsUnderCeiling,
+			start:   cube.Pos{1, 1, 1},
+			target:  cube.Pos{8, 4, 1},
+			wantErr: pathfind.ErrUnreachable,
+		},
+		{
+			name:      "through ground ladder",
+			layers:    []string{floor, groundLadder, corridor},
+			target:    cube.Pos{6, 1, 0},
+			wantCount: 6,
+		},
+		{
+			name:    "enclosed",
+			layers:  []string{floor, enclosure, enclosure},
+			target:  cube.Pos{5, 1, 5},
+			wantErr: pathfind.ErrUnreachable,
+		},
+		{
+			name:       "enclosed in reach range",
+			layers:     []string{floor, enclosure, enclosure},
+			target:     cube.Pos{5, 1, 5},
+			reachRange: 2,
+		},
+		{
+			name:    "closed door",
+			layers:  []string{floor, doorway, doorway},
+			target:  cube.Pos{6, 1, 0},
+			wantErr: pathfind.ErrUnreachable,
+		},
+		{
+			name:   "closed door passed",
+			layers: []string{floor, doorway, doorway},
+			config: evaluator.WalkNodeEvaluatorConfig{CanPathDoors: true, CanOpenDoors: true},
+			target: cube.Pos{6, 1, 0},
+		},
+		{
+			name:            "node budget",
+			layers:          []string{floor, open, open},
+			target:          cube.Pos{6, 1, 6},
+			maxVisitedNodes: 3,
+			wantErr:         pathfind.ErrNodeBudget,
+		},
+		{
+			name:        "distance limit",
+			layers:      []string{floor, open, open},
+			target:      cube.Pos{6, 1, 6},
+			maxDistance: 3,
+			wantErr:     pathfind.ErrDistanceLimit,
+		},
+		{
+			name:      "cancelled",
+			layers:    []string{floor, open, open},
+			target:    cube.Pos{6, 1, 6},
+			cancelled: true,
+			wantErr:   pathfind.ErrCancelled,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.maxVisitedNodes == 0 {
+				tt.maxVisitedNodes = 1000
+			}
+			if tt.maxDistance == 0 {
+				tt.maxDistance = 64
+			}
+			tt.config.Box = cube.Box(-0.3, 0, -0.3, 0.3, 1.8, 0.3)
+			ctx, cancel := context.WithCancel(context.Background())
+			if tt.cancelled {
+				cancel()
+			}
+			defer cancel()
+
+			w := testworld.New(tt.layers...)
+			start := tt.start
+			if start == (cube.Pos{}) {
+				start = cube.Pos{0, 1, 0}
+			}
+			result, err := pathfind.FindPathContext(ctx, tt.config.New(), w, start, tt.target, tt.maxVisitedNodes, tt.maxDistance, tt.reachRange)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("FindPathContext() error = %v, want %v", err, tt.wantErr)
+			}
+			p := result.Path
+			if p.Reached() != (tt.wantErr == nil) {
+				t.Fatalf("Reached() = %v, want %v", p.Reached(), tt.wantErr == nil)
+			}
+			if tt.wantCount != 0 && p.Count() != tt.wantCount {
+				t.Errorf("Count() = %v, want %v", p.Count(), tt.wantCount)
+			}
+			if p.Count() == 0 {
+				return
+			}
+			if p.Reached() {
+				if end := p.EndNode().Pos; manhattan(end, tt.target) > tt.reachRange {
+					t.Errorf("EndNode() = %v, want within %v of %v", end, tt.reachRange, tt.target)
+				}
+			}
+			previous := start
+			for i := 0; i < p.Count(); i++ {
+				pos := p.Node(i).Pos
+				if d := pos.Sub(previous); max(pathfind.Abs(d.X()), pathfind.Abs(d.Z())) > 1 || pathfind.Abs(d.Y()) > 1 {
+					t.Fatalf("node %v at %v is not next to %v", i, pos, previous)
+				}
+				if _, solid := w.Block(pos).(block.Stone); solid {
+					t.Fatalf("node %v at %v is inside a block", i, pos)
+				}
+				previous = pos
+			}
+		})
+	}
+}
+
+// manhattan returns the Manhattan distance between two positions.
+func manhattan(a, b cube.Pos) int {
+	d := a.Sub(b)
+	return pathfind.Abs(d.X()) + pathfind.Abs(d.Y()) + pathfind.Abs(d.Z())
+}
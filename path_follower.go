@@ -0,0 +1,216 @@
+package pathfind
+
+import (
+	"github.com/FDUTCH/Pathfinder/path"
+	"github.com/df-mc/dragonfly/server/block/cube"
+	"github.com/df-mc/dragonfly/server/world"
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+)
+
+// FollowState is the state of a PathFollower after a tick.
+type FollowState byte
+
+const (
+	// FollowStateMoving means the entity is moving towards the next node.
+	FollowStateMoving FollowState = iota
+	// FollowStateDone means there are no nodes left to follow.
+	FollowStateDone
+	// FollowStateStuck means the entity did not get closer to the next node for too long.
+	FollowStateStuck
+	// FollowStateBlocked means the next node cannot be reached from the current position of the entity.
+	FollowStateBlocked
+	// FollowStateInteracting means the entity is waiting for the Action of the next node to be done.
+	FollowStateInteracting
+)
+
+// minProgress is the distance the entity needs to get closer to the next node by for it to count as progress.
+const minProgress = 0.01
+
+// interactDistance is the horizontal distance from a node within which the Action of the node is done.
+const interactDistance = 1.5
+
+// Interactor does the Action of the nodes a PathFollower is about to enter.
+type Interactor interface {
+	// Interact does the Action of the node and reports if the node can be entered. It is called every tick
+	// until it returns true.
+	Interact(tx *world.Tx, ent world.Entity, node *Node) bool
+}
+
+// Movement is the movement a PathFollower computed for a tick.
+type Movement struct {
+	// Velocity is the velocity the entity should move with.
+	Velocity mgl64.Vec3
+	// Rotation is the rotation the entity should look with.
+	Rotation cube.Rotation
+	// Jump specifies if the entity should jump. If true, the Y component of Velocity is the jump velocity.
+	Jump bool
+	// State is the state of the follower.
+	State FollowState
+}
+
+type PathFollowerConfig struct {
+	// Speed is the horizontal speed of the entity in blocks per tick.
+	Speed float64
+	// JumpVelocity is the vertical velocity of a jump. Defaults to 0.42.
+	JumpVelocity float64
+	// StepHeight is the height the entity can step up without jumping. Defaults to 0.6.
+	StepHeight float64
+	// JumpHeight is the max height the entity can reach by jumping. Defaults to 1.125.
+	JumpHeight float64
+	// StuckTicks is the amount of ticks without progress after which the entity is stuck. Defaults to 60.
+	StuckTicks int
+	// Interactor does the Action of nodes, such as opening doors, before the entity enters them. Actions are
+	// ignored if it is nil.
+	Interactor Interactor
+}
+
+func (c PathFollowerConfig) New(p *Path) *PathFollower {
+	if c.JumpVelocity == 0 {
+		c.JumpVelocity = 0.42
+	}
+	if c.StepHeight == 0 {
+		c.StepHeight = 0.6
+	}
+	if c.JumpHeight == 0 {
+		c.JumpHeight = 1.125
+	}
+	if c.StuckTicks == 0 {
+		c.StuckTicks = 60
+	}
+	f := &PathFollower{conf: c}
+	f.SetPath(p)
+	return f
+}
+
+// PathFollower steers an entity along a Path. It computes the Movement of the entity each tick and advances
+// the path when the entity reaches a node.
+type PathFollower struct {
+	conf PathFollowerConfig
+	path *Path
+
+	bestDistance         float64
+	ticksWithoutProgress int
+
+	// interacted is the node of which the Action was done last.
+	interacted *Node
+}
+
+// Path returns the path being followed.
+func (f *PathFollower) Path() *Path {
+	return f.path
+}
+
+// SetPath replaces the path being followed.
+func (f *PathFollower) SetPath(p *Path) {
+	f.path = p
+	f.interacted = nil
+	f.resetProgress()
+}
+
+// SetSpeed changes the horizontal speed of the entity.
+func (f *PathFollower) SetSpeed(speed float64) {
+	f.conf.Speed = speed
+}
+
+// Tick computes the Movement of the entity for the current tick and does the Action of the next node once the
+// entity is close to it. The entity is passed every tick, as an entity may only be used within the
+// transaction it was obtained in.
+func (f *PathFollower) Tick(tx *world.Tx, ent world.Entity) Movement {
+	if f.path == nil || f.path.IsDone() {
+		return Movement{Rotation: ent.Rotation(), State: FollowStateDone}
+	}
+	pos := ent.Position()
+	tolerance := f.waypointTolerance(ent)
+
+	for !f.path.IsDone() && f.reached(pos, f.path.NextEntityPosition(ent), tolerance) {
+		f.path.Advance()
+		f.resetProgress()
+	}
+	if f.path.IsDone() {
+		return Movement{Rotation: ent.Rotation(), State: FollowStateDone}
+	}
+
+	next := f.path.NextNode()
+	target := f.path.NextEntityPosition(ent)
+	delta := target.Sub(pos)
+	horizontal := mgl64.Vec2{delta.X(), delta.Z()}
+
+	m := Movement{Rotation: rotationTowards(delta), State: FollowStateMoving}
+	if next.CostMalus < 0 || (delta.Y() > f.conf.JumpHeight && next.Type != path.CLIMBABLE && next.Type != path.WATER) {
+		m.State = FollowStateBlocked
+		return m
+	}
+	if !f.interact(tx, ent, next, horizontal.Len()) {
+		m.State = FollowStateInteracting
+		f.resetProgress()
+		return m
+	}
+
+	if distance := horizontal.Len(); distance > mgl64.Epsilon {
+		direction := horizontal.Mul(min(f.conf.Speed, distance) / distance)
+		m.Velocity = mgl64.Vec3{direction.X(), 0, direction.Y()}
+	}
+	if delta.Y() > f.conf.StepHeight && next.Type != path.CLIMBABLE {
+		m.Jump = true
+		m.Velocity[1] = f.conf.JumpVelocity
+	}
+
+	if f.progress(delta.Len()) {
+		m.State = FollowStateStuck
+	}
+	return m
+}
+
+// interact does the Action of the next node through the Interactor once the entity is within distance of it.
+// It reports if the entity may move into the node.
+func (f *PathFollower) interact(tx *world.Tx, ent world.Entity, next *Node, distance float64) bool {
+	if next.Action == ActionNone || f.conf.Interactor == nil || f.interacted == next || distance > interactDistance {
+		return true
+	}
+	if !f.conf.Interactor.Interact(tx, ent, next) {
+		return false
+	}
+	f.interacted = next
+	return true
+}
+
+// waypointTolerance returns the horizontal distance from a node within which the node counts as reached.
+func (f *PathFollower) waypointTolerance(ent world.Entity) float64 {
+	width := ent.H().Type().BBox(ent).Width()
+	if width > 0.75 {
+		return width / 2
+	}
+	return 0.75 - width/2
+}
+
+// reached checks if the entity at pos reached the target position.
+func (f *PathFollower) reached(pos, target mgl64.Vec3, tolerance float64) bool {
+	horizontal := mgl64.Vec2{target.X() - pos.X(), target.Z() - pos.Z()}
+	return horizontal.Len() < tolerance && math.Abs(target.Y()-pos.Y()) < 1
+}
+
+// progress records the distance to the next node and reports if the entity is stuck.
+func (f *PathFollower) progress(distance float64) (stuck bool) {
+	if distance < f.bestDistance-minProgress {
+		f.bestDistance = distance
+		f.ticksWithoutProgress = 0
+		return false
+	}
+	f.ticksWithoutProgress++
+	return f.ticksWithoutProgress >= f.conf.StuckTicks
+}
+
+// resetProgress resets the stuck detection.
+func (f *PathFollower) resetProgress() {
+	f.bestDistance = math.Inf(1)
+	f.ticksWithoutProgress = 0
+}
+
+// rotationTowards returns the rotation looking in the direction passed.
+func rotationTowards(direction mgl64.Vec3) cube.Rotation {
+	horizontal := math.Hypot(direction.X(), direction.Z())
+	yaw := mgl64.RadToDeg(math.Atan2(-direction.X(), direction.Z()))
+	pitch := mgl64.RadToDeg(-math.Atan2(direction.Y(), horizontal))
+	return cube.Rotation{yaw, pitch}
+}
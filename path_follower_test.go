@@ -0,0 +1,180 @@
+package pathfind_test
+
+import (
+	"testing"
+
+	pathfind "github.com/FDUTCH/Pathfinder"
+	"github.com/FDUTCH/Pathfinder/path"
+	"github.com/df-mc/dragonfly/server/block/cube"
+	"github.com/df-mc/dragonfly/server/world"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// walkerType is the world.EntityType of a walker, which is player sized.
+type walkerType struct{}
+
+func (walkerType) Open(*world.Tx, *world.EntityHandle, *world.EntityData) world.Entity { return nil }
+func (walkerType) EncodeEntity() string                                                { return "pathfinder:walker" }
+func (walkerType) BBox(world.Entity) cube.BBox                                         { return cube.Box(-0.3, 0, -0.3, 0.3, 1.8, 0.3) }
+func (walkerType) DecodeNBT(map[string]any, *world.EntityData)                         {}
+func (walkerType) EncodeNBT(*world.EntityData) map[string]any                          { return nil }
+func (walkerType) Apply(*world.EntityData)                                             {}
+
+// walker is a world.Entity outside of any world that tests move by hand.
+type walker struct {
+	handle *world.EntityHandle
+	pos    mgl64.Vec3
+}
+
+// newWalker returns a walker at the position passed.
+func newWalker(pos mgl64.Vec3) *walker {
+	return &walker{handle: world.EntitySpawnOpts{Position: pos}.New(walkerType{}, walkerType{}), pos: pos}
+}
+
+func (w *walker) H() *world.EntityHandle  { return w.handle }
+func (w *walker) Position() mgl64.Vec3    { return w.pos }
+func (w *walker) Rotation() cube.Rotation { return cube.Rotation{} }
+func (w *walker) Close() error            { return nil }
+
+// line returns a path along the x axis at y=1 and z=0 through the x coordinates passed.
+func line(xs ...int) *pathfind.Path {
+	nodes := make([]*pathfind.Node, 0, len(xs))
+	for _, x := range xs {
+		nodes = append(nodes, pathfind.NewNode(cube.Pos{x, 1, 0}))
+	}
+	return pathfind.NewPath(nodes, true, nodes[len(nodes)-1].Pos)
+}
+
+// countingInteractor is a pathfind.Interactor that lets nodes be entered after a number of calls.
+type countingInteractor struct {
+	calls, need int
+}
+
+func (i *countingInteractor) Interact(*world.Tx, world.Entity, *pathfind.Node) bool {
+	i.calls++
+	return i.calls >= i.need
+}
+
+func TestPathFollowerTick(t *testing.T) {
+	ent := newWalker(mgl64.Vec3{0.5, 1, 0.5})
+	f := pathfind.PathFollowerConfig{Speed: 0.25}.New(line(1, 2, 3))
+
+	for tick := 0; tick < 100; tick++ {
+		m := f.Tick(nil, ent)
+		if m.State == pathfind.FollowStateDone {
+			if end := (mgl64.Vec3{3.5, 1, 0.5}); ent.pos.Sub(end).Len() > 0.75 {
+				t.Errorf("Position() when done = %v, want near %v", ent.pos, end)
+			}
+			return
+		}
+		if m.State != pathfind.FollowStateMoving {
+			t.Fatalf("State at tick %v = %v, want %v", tick, m.State, pathfind.FollowStateMoving)
+		}
+		if speed := m.Velocity.Len(); speed > 0.25+mgl64.Epsilon {
+			t.Fatalf("Velocity at tick %v = %v, want at most 0.25 blocks per tick", tick, m.Velocity)
+		}
+		if m.Velocity.X() <= 0 || m.Jump {
+			t.Fatalf("Movement at tick %v = %+v, want walking towards +x", tick, m)
+		}
+		ent.pos = ent.pos.Add(m.Velocity)
+	}
+	t.Fatalf("path not done after 100 ticks, next node %v", f.Path().NextNodeIndex())
+}
+
+func TestPathFollowerWaypoint(t *testing.T) {
+	// The entity is within the tolerance of the first node, which is 0.45 blocks for its width.
+	ent := newWalker(mgl64.Vec3{1.9, 1, 0.5})
+	f := pathfind.PathFollowerConfig{Speed: 0.25}.New(line(1, 2, 3))
+	if m := f.Tick(nil, ent); m.State != pathfind.FollowStateMoving || m.Velocity.X() <= 0 {
+		t.Fatalf("Tick() = %+v, want %v towards +x", m, pathfind.FollowStateMoving)
+	}
+	if got := f.Path().NextNodeIndex(); got != 1 {
+		t.Errorf("NextNodeIndex() = %v, want 1", got)
+	}
+
+	// Nodes a block higher or lower are not reached, even if the entity is right above or below them.
+	f.SetPath(line(1, 2))
+	f.Tick(nil, newWalker(mgl64.Vec3{1.5, 2, 0.5}))
+	if got := f.Path().NextNodeIndex(); got != 0 {
+		t.Errorf("NextNodeIndex() from a block above = %v, want 0", got)
+	}
+}
+
+func TestPathFollowerStuck(t *testing.T) {
+	const stuckTicks = 5
+	ent := newWalker(mgl64.Vec3{0.5, 1, 0.5})
+	f := pathfind.PathFollowerConfig{Speed: 0.25, StuckTicks: stuckTicks}.New(line(3))
+
+	// The first tick records the distance to the node, every following tick without progress counts.
+	for tick := 0; tick < stuckTicks; tick++ {
+		if m := f.Tick(nil, ent); m.State != pathfind.FollowStateMoving {
+			t.Fatalf("State at tick %v = %v, want %v", tick, m.State, pathfind.FollowStateMoving)
+		}
+	}
+	if m := f.Tick(nil, ent); m.State != pathfind.FollowStateStuck {
+		t.Fatalf("State after %v ticks without progress = %v, want %v", stuckTicks, m.State, pathfind.FollowStateStuck)
+	}
+
+	// Moving closer resets the count.
+	ent.pos = ent.pos.Add(mgl64.Vec3{0.25})
+	if m := f.Tick(nil, ent); m.State != pathfind.FollowStateMoving {
+		t.Errorf("State after progress = %v, want %v", m.State, pathfind.FollowStateMoving)
+	}
+}
+
+func TestPathFollowerHeight(t *testing.T) {
+	tests := []struct {
+		name      string
+		y         int
+		pathType  path.BlockPathType
+		wantState pathfind.FollowState
+		wantJump  bool
+	}{
+		{name: "step up", y: 2, pathType: path.WALKABLE, wantState: pathfind.FollowStateMoving, wantJump: true},
+		{name: "too high", y: 3, pathType: path.WALKABLE, wantState: pathfind.FollowStateBlocked},
+		{name: "climbing", y: 3, pathType: path.CLIMBABLE, wantState: pathfind.FollowStateMoving},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := pathfind.NewNode(cube.Pos{1, tt.y, 0})
+			node.Type = tt.pathType
+			f := pathfind.PathFollowerConfig{Speed: 0.25}.New(pathfind.NewPath([]*pathfind.Node{node}, true, node.Pos))
+
+			m := f.Tick(nil, newWalker(mgl64.Vec3{0.5, 1, 0.5}))
+			if m.State != tt.wantState || m.Jump != tt.wantJump {
+				t.Fatalf("Tick() = %+v, want state %v and jump %v", m, tt.wantState, tt.wantJump)
+			}
+			if m.Jump && m.Velocity.Y() != 0.42 {
+				t.Errorf("Velocity.Y() of jump = %v, want 0.42", m.Velocity.Y())
+			}
+		})
+	}
+}
+
+func TestPathFollowerInteractor(t *testing.T) {
+	interactor := &countingInteractor{need: 3}
+	p := line(2, 3)
+	p.Node(1).Action = pathfind.ActionOpenDoor
+	f := pathfind.PathFollowerConfig{Speed: 0.25, Interactor: interactor}.New(p)
+
+	// The node with the action is not the next one yet.
+	ent := newWalker(mgl64.Vec3{1.5, 1, 0.5})
+	if m := f.Tick(nil, ent); m.State != pathfind.FollowStateMoving || interactor.calls != 0 {
+		t.Fatalf("Tick() before the node = %v with %v calls, want %v without calls", m.State, interactor.calls, pathfind.FollowStateMoving)
+	}
+
+	ent.pos = mgl64.Vec3{2.4, 1, 0.5}
+	for tick := 1; tick < interactor.need; tick++ {
+		if m := f.Tick(nil, ent); m.State != pathfind.FollowStateInteracting || m.Velocity != (mgl64.Vec3{}) {
+			t.Fatalf("Tick() %v = %+v, want %v without velocity", tick, m, pathfind.FollowStateInteracting)
+		}
+	}
+	for tick := 0; tick < 2; tick++ {
+		if m := f.Tick(nil, ent); m.State != pathfind.FollowStateMoving {
+			t.Fatalf("Tick() after interacting = %v, want %v", m.State, pathfind.FollowStateMoving)
+		}
+	}
+	if interactor.calls != interactor.need {
+		t.Errorf("Interact() called %v times, want %v", interactor.calls, interactor.need)
+	}
+}
@@ -0,0 +1,223 @@
+package pathfind_test
+
+import (
+	"slices"
+	"testing"
+
+	pathfind "github.com/FDUTCH/Pathfinder"
+	"github.com/FDUTCH/Pathfinder/evaluator"
+	"github.com/FDUTCH/Pathfinder/internal/testworld"
+	"github.com/df-mc/dragonfly/server/block"
+	"github.com/df-mc/dragonfly/server/block/cube"
+	"github.com/df-mc/dragonfly/server/world"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// straightPath returns a world of open ground and the path along z=3 across it, from x=0 to x=6.
+func straightPath(t *testing.T) (*testworld.World, *evaluator.WalkNodeEvaluator, *pathfind.Path) {
+	w := testworld.New(floor, open, open)
+	e := evaluator.WalkNodeEvaluatorConfig{Box: cube.Box(-0.3, 0, -0.3, 0.3, 1.8, 0.3)}.New()
+	p := pathfind.FindPath(e, w, cube.Pos{0, 1, 3}, cube.Pos{6, 1, 3}, 1000, 64, 0)
+	if !p.Reached() || p.Count() != 6 {
+		t.Fatalf("FindPath() = %v nodes, reached %v, want 6 nodes, reached", p.Count(), p.Reached())
+	}
+	return w, e, p
+}
+
+// nodes returns the nodes of the path passed.
+func nodes(p *pathfind.Path) []*pathfind.Node {
+	n := make([]*pathfind.Node, p.Count())
+	for i := range n {
+		n[i] = p.Node(i)
+	}
+	return n
+}
+
+// checkConnected checks that the nodes passed are valid and each next to the one before it.
+func checkConnected(t *testing.T, w *testworld.World, e *evaluator.WalkNodeEvaluator, nodes []*pathfind.Node) {
+	t.Helper()
+	for i, n := range nodes {
+		if !e.ValidNode(w, n) {
+			t.Errorf("node %v at %v is not valid", i, n.Pos)
+		}
+		if i > 0 {
+			if d := n.Pos.Sub(nodes[i-1].Pos); max(pathfind.Abs(d.X()), pathfind.Abs(d.Y()), pathfind.Abs(d.Z())) > 1 {
+				t.Errorf("node %v at %v is not next to %v", i, n.Pos, nodes[i-1].Pos)
+			}
+		}
+	}
+}
+
+func TestPathFirstInvalidNode(t *testing.T) {
+	w, e, p := straightPath(t)
+	if got := p.FirstInvalidNode(w, e); got != -1 {
+		t.Fatalf("FirstInvalidNode() = %v, want -1", got)
+	}
+	w.Fill(cube.Pos{3, 1, 3}, cube.Pos{4, 2, 3}, block.Stone{})
+	if got := p.FirstInvalidNode(w, e); got != 2 || p.Node(got).Pos != (cube.Pos{3, 1, 3}) {
+		t.Errorf("FirstInvalidNode() = %v, want 2 at %v", got, cube.Pos{3, 1, 3})
+	}
+	// Nodes before the next node are not checked.
+	p.SetNextNodeIndex(4)
+	if got := p.FirstInvalidNode(w, e); got != -1 {
+		t.Errorf("FirstInvalidNode() after the blocked nodes = %v, want -1", got)
+	}
+}
+
+func TestPathRepath(t *testing.T) {
+	w, e, p := straightPath(t)
+	original := nodes(p)
+	p.SetNextNodeIndex(1)
+	w.Fill(cube.Pos{3, 1, 3}, cube.Pos{4, 2, 3}, block.Stone{})
+
+	if !p.Repath(e, e, w, p.PreviousNode().Pos, 1000, 64, 0) {
+		t.Fatal("Repath() = false, want true")
+	}
+	got := nodes(p)
+	if got[0] != original[0] {
+		t.Errorf("Node(0) = %v, want the passed node %v kept", got[0].Pos, original[0].Pos)
+	}
+	// The detour rejoins the path at x=5, the node after it is kept.
+	if got[len(got)-1] != original[len(original)-1] {
+		t.Errorf("EndNode() = %v, want the original end node", got[len(got)-1].Pos)
+	}
+	if !slices.ContainsFunc(got, func(n *pathfind.Node) bool { return n.Pos == cube.Pos{5, 1, 3} }) {
+		t.Errorf("path %v does not rejoin at %v", got, cube.Pos{5, 1, 3})
+	}
+	checkConnected(t, w, e, got)
+	if p.NextNodeIndex() != 1 {
+		t.Errorf("NextNodeIndex() = %v, want 1", p.NextNodeIndex())
+	}
+}
+
+func TestPathRepathObstructions(t *testing.T) {
+	// Both obstructions are detoured, rejoining the path at x=3 and x=5.
+	w, e, p := straightPath(t)
+	original := nodes(p)
+	p.SetNextNodeIndex(1)
+	w.Fill(cube.Pos{2, 1, 3}, cube.Pos{2, 2, 3}, block.Stone{})
+	w.Fill(cube.Pos{4, 1, 3}, cube.Pos{4, 2, 3}, block.Stone{})
+
+	if !p.Repath(e, e, w, p.PreviousNode().Pos, 1000, 64, 0) {
+		t.Fatal("Repath() = false, want true")
+	}
+	if got := p.FirstInvalidNode(w, e); got != -1 {
+		t.Errorf("FirstInvalidNode() after repathing = %v at %v, want -1", got, p.Node(got).Pos)
+	}
+	got := nodes(p)
+	if got[len(got)-1] != original[len(original)-1] {
+		t.Errorf("EndNode() = %v, want the original end node", got[len(got)-1].Pos)
+	}
+	checkConnected(t, w, e, got)
+}
+
+func TestPathRepathTarget(t *testing.T) {
+	// Without a valid node after the blocked ones, a new path to the target is searched for.
+	w, e, p := straightPath(t)
+	p.SetNextNodeIndex(1)
+	w.Fill(cube.Pos{4, 1, 3}, cube.Pos{6, 2, 3}, block.Stone{})
+
+	if p.Repath(e, e, w, p.PreviousNode().Pos, 1000, 64, 0) {
+		t.Error("Repath() to a blocked target = true, want false")
+	}
+	if p.Reached() {
+		t.Error("Reached() after repathing to a blocked target = true, want false")
+	}
+	if got := p.FirstInvalidNode(w, e); got != -1 {
+		t.Errorf("FirstInvalidNode() after repathing = %v, want -1", got)
+	}
+}
+
+func TestPathSmooth(t *testing.T) {
+	tests := []struct {
+		name          string
+		layers        []string
+		config        evaluator.WalkNodeEvaluatorConfig
+		start, target cube.Pos
+		// via holds positions the smoothed path must still pass.
+		via       []cube.Pos
+		wantCount int
+	}{
+		{
+			name:      "open ground",
+			layers:    []string{floor, open, open},
+			start:     cube.Pos{0, 1, 0},
+			target:    cube.Pos{6, 1, 3},
+			wantCount: 2,
+		},
+		{
+			name:   "drop",
+			layers: []string{floor, trench, open, open},
+			start:  cube.Pos{0, 2, 3},
+			target: cube.Pos{6, 2, 3},
+			via:    []cube.Pos{{3, 1, 3}},
+		},
+		{
+			name:   "door",
+			layers: []string{floor, doorway, doorway},
+			config: evaluator.WalkNodeEvaluatorConfig{CanPathDoors: true, CanOpenDoors: true},
+			start:  cube.Pos{0, 1, 3},
+			target: cube.Pos{6, 1, 3},
+			via:    []cube.Pos{{3, 1, 3}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := testworld.New(tt.layers...)
+			tt.config.Box = cube.Box(-0.3, 0, -0.3, 0.3, 1.8, 0.3)
+			e := tt.config.New()
+			p := pathfind.FindPath(e, w, tt.start, tt.target, 1000, 64, 0)
+			first, end := p.Node(0), p.EndNode()
+
+			p.Smooth(w, e)
+			if tt.wantCount != 0 && p.Count() != tt.wantCount {
+				t.Errorf("Count() = %v, want %v", p.Count(), tt.wantCount)
+			}
+			if p.Node(0) != first || p.EndNode() != end {
+				t.Errorf("Smooth() changed the first or last node")
+			}
+			for _, pos := range tt.via {
+				if !slices.ContainsFunc(nodes(p), func(n *pathfind.Node) bool { return n.Pos == pos }) {
+					t.Errorf("smoothed path does not pass %v", pos)
+				}
+			}
+		})
+	}
+}
+
+// sizedType is the world.EntityType of a walker with a square bounding box of a width.
+type sizedType struct {
+	walkerType
+	width float64
+}
+
+func (t sizedType) BBox(world.Entity) cube.BBox {
+	return cube.Box(-t.width/2, 0, -t.width/2, t.width/2, 1.8, t.width/2)
+}
+
+func TestPathEntityPosAtNode(t *testing.T) {
+	tests := []struct {
+		name  string
+		width float64
+		// want is the offset of the position from the corner of the node on the x and z axes.
+		want float64
+	}{
+		// (width+1)*0.5 put entities narrower than a block past the middle of the node.
+		{name: "player", width: 0.6, want: 0.5},
+		{name: "almost a block", width: 0.98, want: 0.5},
+		{name: "block", width: 1, want: 1},
+		{name: "wider than a block", width: 1.4, want: 1},
+		{name: "two blocks", width: 2, want: 1.5},
+	}
+	p := line(2, 3)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			typ := sizedType{width: tt.width}
+			ent := &walker{handle: world.EntitySpawnOpts{}.New(typ, typ)}
+			want := mgl64.Vec3{3 + tt.want, 1, tt.want}
+			if got := p.EntityPosAtNode(ent, 1); got != want {
+				t.Errorf("EntityPosAtNode() = %v, want %v", got, want)
+			}
+		})
+	}
+}
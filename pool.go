@@ -0,0 +1,210 @@
+package pathfind
+
+import (
+	"context"
+	"errors"
+	"github.com/df-mc/dragonfly/server/block/cube"
+	"github.com/df-mc/dragonfly/server/world"
+	"sync"
+)
+
+var (
+	// ErrQueueFull is returned when a request is submitted to a Pool with a full queue.
+	ErrQueueFull = errors.New("pathfind: pool queue is full")
+	// ErrPoolClosed is returned when a request is submitted to a closed Pool.
+	ErrPoolClosed = errors.New("pathfind: pool is closed")
+	// ErrDistanceOutOfRange is returned when a request is submitted to a Pool with a max distance from start
+	// that is negative or above MaxSnapshotDistance.
+	ErrDistanceOutOfRange = errors.New("pathfind: max distance from start out of range")
+)
+
+// Request is a path search request handled by a Pool.
+type Request struct {
+	// Key deduplicates requests: while a request with the same non-nil Key is pending, new requests
+	// with that Key receive its Result instead of starting another search. Key must be comparable.
+	Key any
+	// Evaluator is the NodeEvaluator used for the search. It must not be used elsewhere until the Result
+	// of the request was delivered.
+	Evaluator       NodeEvaluator
+	Pos             cube.Pos
+	Targets         []cube.Pos
+	MaxVisitedNodes int
+	// MaxDistanceFromStart limits the search and the region copied for it. It must be between 0 and
+	// MaxSnapshotDistance.
+	MaxDistanceFromStart float64
+	ReachRange           int
+	// Heuristic is the Heuristic used for the search. The default one is used if nil.
+	Heuristic Heuristic
+	// Margin, if above 0, limits the region copied for the request to the box spanning Pos and Targets grown
+	// by Margin blocks, see SnapshotBetween. Otherwise, the whole region within MaxDistanceFromStart of Pos is
+	// copied, see SnapshotAround.
+	Margin int
+}
+
+// snapshot copies the region of the source that the search of the request may read.
+func (req Request) snapshot(source world.BlockSource) *Snapshot {
+	if req.Margin > 0 {
+		return SnapshotBetween(source, req.Pos, req.Targets, req.MaxDistanceFromStart, req.Margin)
+	}
+	return SnapshotAround(source, req.Pos, req.MaxDistanceFromStart)
+}
+
+// Pool runs path searches on background goroutines. The blocks a search needs are copied into a Snapshot
+// when the request is submitted, so the search itself never touches the world.
+type Pool struct {
+	queue  chan *job
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	closed  bool
+	active  int
+	pending map[any]*job
+}
+
+// job is a request queued in a Pool.
+type job struct {
+	req       Request
+	source    world.BlockSource
+	callbacks []func(Result)
+}
+
+// NewPool starts a Pool with the amount of workers passed that can queue up to queueSize requests. If
+// queueSize is 0, requests are only accepted while a worker is idle.
+func NewPool(workers, queueSize int) *Pool {
+	workers = max(workers, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Pool{
+		queue:   make(chan *job, max(queueSize, 0)),
+		ctx:     ctx,
+		cancel:  cancel,
+		pending: make(map[any]*job),
+	}
+	p.wg.Add(workers)
+	for range workers {
+		go p.work()
+	}
+	return p
+}
+
+// Submit queues the request and returns a channel that receives its Result. The source is only read during
+// the call, so it may be a world transaction. The blocks are copied on the calling goroutine, which may take
+// a while for long requests without a Margin, see SnapshotAround.
+func (p *Pool) Submit(source world.BlockSource, req Request) (<-chan Result, error) {
+	ch := make(chan Result, 1)
+	err := p.SubmitFunc(source, req, func(result Result) {
+		ch <- result
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ch, nil
+}
+
+// SubmitFunc queues the request and calls f with its Result from a worker goroutine. The source is only
+// read during the call, so it may be a world transaction.
+func (p *Pool) SubmitFunc(source world.BlockSource, req Request, f func(Result)) error {
+	if len(req.Targets) == 0 {
+		return ErrNoTargets
+	}
+	if !(req.MaxDistanceFromStart >= 0 && req.MaxDistanceFromStart <= MaxSnapshotDistance) {
+		return ErrDistanceOutOfRange
+	}
+	if queued, err := p.join(req, f); queued || err != nil {
+		return err
+	}
+
+	// The region is copied without holding the lock, so that other requests are not held up by it.
+	j := &job{
+		req:       req,
+		source:    req.snapshot(source),
+		callbacks: []func(Result){f},
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	// The pool may have been closed or a request with the same key submitted during the copy.
+	if queued, err := p.joinLocked(req, f); queued || err != nil {
+		return err
+	}
+	select {
+	case p.queue <- j:
+	default:
+		return ErrQueueFull
+	}
+	p.active++
+	if req.Key != nil {
+		p.pending[req.Key] = j
+	}
+	return nil
+}
+
+// join adds f to the callbacks of the pending request with the same key as req if there is one. It reports
+// true if so, or returns an error if the request cannot be queued.
+func (p *Pool) join(req Request, f func(Result)) (bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.joinLocked(req, f)
+}
+
+// joinLocked is join for callers holding p.mu.
+func (p *Pool) joinLocked(req Request, f func(Result)) (bool, error) {
+	if p.closed {
+		return false, ErrPoolClosed
+	}
+	if req.Key != nil {
+		if j, ok := p.pending[req.Key]; ok {
+			j.callbacks = append(j.callbacks, f)
+			return true, nil
+		}
+	}
+	if cap(p.queue) > 0 && len(p.queue) == cap(p.queue) {
+		return false, ErrQueueFull
+	}
+	return false, nil
+}
+
+// Pending returns the amount of requests queued or running.
+func (p *Pool) Pending() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.active
+}
+
+// Close cancels all pending searches, delivering their partial results, and waits for the workers to stop.
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	p.mu.Unlock()
+
+	p.cancel()
+	close(p.queue)
+	p.wg.Wait()
+	return nil
+}
+
+// work runs queued jobs until the queue is closed.
+func (p *Pool) work() {
+	defer p.wg.Done()
+	for j := range p.queue {
+		req := j.req
+		result, _ := FindPathMultiContext(p.ctx, req.Evaluator, j.source, req.Pos, req.Targets, req.MaxVisitedNodes, req.MaxDistanceFromStart, req.ReachRange, req.Heuristic)
+
+		p.mu.Lock()
+		p.active--
+		if req.Key != nil && p.pending[req.Key] == j {
+			delete(p.pending, req.Key)
+		}
+		callbacks := j.callbacks
+		p.mu.Unlock()
+
+		for _, f := range callbacks {
+			f(result)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package pathfind_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	pathfind "github.com/FDUTCH/Pathfinder"
+	"github.com/FDUTCH/Pathfinder/evaluator"
+	"github.com/FDUTCH/Pathfinder/internal/testworld"
+	"github.com/df-mc/dragonfly/server/block"
+	"github.com/df-mc/dragonfly/server/block/cube"
+	"github.com/df-mc/dragonfly/server/world"
+)
+
+// blockingEvaluator is a NodeEvaluator that waits for release before each search starts, so that tests can
+// keep the workers of a Pool busy.
+type blockingEvaluator struct {
+	pathfind.NodeEvaluator
+	started, release chan struct{}
+}
+
+// walkEvaluator returns a NodeEvaluator for a player sized entity.
+func walkEvaluator() pathfind.NodeEvaluator {
+	return evaluator.WalkNodeEvaluatorConfig{Box: cube.Box(-0.3, 0, -0.3, 0.3, 1.8, 0.3)}.New()
+}
+
+func newBlockingEvaluator() blockingEvaluator {
+	return blockingEvaluator{
+		NodeEvaluator: walkEvaluator(),
+		started:       make(chan struct{}, 1),
+		release:       make(chan struct{}),
+	}
+}
+
+func (e blockingEvaluator) Prepare(source world.BlockSource, pos cube.Pos) {
+	e.started <- struct{}{}
+	<-e.release
+	e.NodeEvaluator.Prepare(source, pos)
+}
+
+// poolRequest returns a request for a path along the floor of the straight fixture.
+func poolRequest(e pathfind.NodeEvaluator, key any) pathfind.Request {
+	return pathfind.Request{Key: key, Evaluator: e, Pos: cube.Pos{0, 1, 0}, Targets: []cube.Pos{{6, 1, 0}}, MaxVisitedNodes: 1000, MaxDistanceFromStart: 32}
+}
+
+// receive returns the Result received from the channel passed, failing the test if none arrives in time.
+func receive(t *testing.T, ch <-chan pathfind.Result) pathfind.Result {
+	t.Helper()
+	select {
+	case result := <-ch:
+		return result
+	case <-time.After(5 * time.Second):
+		t.Fatal("no result received")
+		return pathfind.Result{}
+	}
+}
+
+func TestPool(t *testing.T) {
+	w := testworld.New(floor, open, open)
+	p := pathfind.NewPool(1, 2)
+	defer p.Close()
+
+	busy := newBlockingEvaluator()
+	first, err := p.Submit(w, poolRequest(busy, nil))
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	<-busy.started
+
+	// The worker is busy, so requests with the same key share the queued search.
+	e := newBlockingEvaluator()
+	second, err := p.Submit(w, poolRequest(e, "key"))
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	third, err := p.Submit(w, poolRequest(e, "key"))
+	if err != nil {
+		t.Fatalf("Submit() with pending key error = %v", err)
+	}
+	if _, err := p.Submit(w, poolRequest(walkEvaluator(), nil)); err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	if _, err := p.Submit(w, poolRequest(walkEvaluator(), nil)); !errors.Is(err, pathfind.ErrQueueFull) {
+		t.Fatalf("Submit() with full queue error = %v, want %v", err, pathfind.ErrQueueFull)
+	}
+	if got := p.Pending(); got != 3 {
+		t.Errorf("Pending() = %v, want 3", got)
+	}
+
+	close(busy.release)
+	if result := receive(t, first); result.Reason != pathfind.TerminationReached {
+		t.Errorf("first result reason = %v, want %v", result.Reason, pathfind.TerminationReached)
+	}
+	<-e.started
+	close(e.release)
+	if a, b := receive(t, second), receive(t, third); a.Path != b.Path || !a.Path.Reached() {
+		t.Errorf("results of requests with the same key = %v, %v, want the same reached path", a.Path, b.Path)
+	}
+}
+
+func TestPoolUnbuffered(t *testing.T) {
+	w := testworld.New(floor, open, open)
+	p := pathfind.NewPool(1, 0)
+	defer p.Close()
+
+	// Requests are accepted as soon as the worker waits for one.
+	busy := newBlockingEvaluator()
+	deadline := time.Now().Add(5 * time.Second)
+	_, err := p.Submit(w, poolRequest(busy, nil))
+	for errors.Is(err, pathfind.ErrQueueFull) && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+		_, err = p.Submit(w, poolRequest(busy, nil))
+	}
+	if err != nil {
+		t.Fatalf("Submit() to idle worker error = %v", err)
+	}
+	<-busy.started
+	if _, err := p.Submit(w, poolRequest(walkEvaluator(), nil)); !errors.Is(err, pathfind.ErrQueueFull) {
+		t.Errorf("Submit() to busy worker error = %v, want %v", err, pathfind.ErrQueueFull)
+	}
+	close(busy.release)
+}
+
+func TestPoolMargin(t *testing.T) {
+	w := testworld.New(floor, open, open)
+	// Blocks outside the region copied are barriers, so the wall reaches below and beside the fixture to keep
+	// paths from walking on them around it.
+	w.Fill(cube.Pos{3, -4, -4}, cube.Pos{3, 2, 1}, block.Stone{})
+	p := pathfind.NewPool(1, 1)
+	defer p.Close()
+
+	// The wall is passed at z=2, so only a margin of 2 blocks copies the way around it.
+	for margin, want := range map[int]bool{1: false, 2: true} {
+		req := poolRequest(walkEvaluator(), nil)
+		req.Margin = margin
+		ch, err := p.Submit(w, req)
+		if err != nil {
+			t.Fatalf("Submit() error = %v", err)
+		}
+		if result := receive(t, ch); result.Path.Reached() != want {
+			t.Errorf("Path.Reached() with margin %v = %v, want %v", margin, result.Path.Reached(), want)
+		}
+	}
+}
+
+func TestPoolInvalidRequest(t *testing.T) {
+	w := testworld.New(floor, open, open)
+	p := pathfind.NewPool(1, 1)
+
+	req := poolRequest(walkEvaluator(), nil)
+	req.Targets = nil
+	if _, err := p.Submit(w, req); !errors.Is(err, pathfind.ErrNoTargets) {
+		t.Errorf("Submit() without targets error = %v, want %v", err, pathfind.ErrNoTargets)
+	}
+	for _, distance := range []float64{-1, pathfind.MaxSnapshotDistance + 1, math.Inf(1), math.NaN()} {
+		req := poolRequest(walkEvaluator(), nil)
+		req.MaxDistanceFromStart = distance
+		if _, err := p.Submit(w, req); !errors.Is(err, pathfind.ErrDistanceOutOfRange) {
+			t.Errorf("Submit() with distance %v error = %v, want %v", distance, err, pathfind.ErrDistanceOutOfRange)
+		}
+	}
+
+	if err := p.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if _, err := p.Submit(w, poolRequest(walkEvaluator(), nil)); !errors.Is(err, pathfind.ErrPoolClosed) {
+		t.Errorf("Submit() to closed pool error = %v, want %v", err, pathfind.ErrPoolClosed)
+	}
+}
@@ -0,0 +1,87 @@
+package pathfind
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrUnreachable is returned when the open set was exhausted before the target was reached.
+	ErrUnreachable = errors.New("pathfind: target is unreachable")
+	// ErrNodeBudget is returned when the search visited the maximum amount of nodes allowed.
+	ErrNodeBudget = errors.New("pathfind: max visited nodes exceeded")
+	// ErrDistanceLimit is returned when the search could not continue without leaving the max distance from start.
+	ErrDistanceLimit = errors.New("pathfind: max distance from start exceeded")
+	// ErrCancelled is returned when the context passed to the search was cancelled.
+	ErrCancelled = errors.New("pathfind: search cancelled")
+	// ErrNoTargets is returned when a multi-target search is started without any targets.
+	ErrNoTargets = errors.New("pathfind: no targets passed")
+	// ErrInProgress is returned when the result of a Search is requested before it is finished.
+	ErrInProgress = errors.New("pathfind: search in progress")
+)
+
+// TerminationReason represents the reason a path search stopped.
+type TerminationReason byte
+
+const (
+	// TerminationReached means the target was reached.
+	TerminationReached TerminationReason = iota
+	// TerminationExhausted means the open set was exhausted without reaching the target.
+	TerminationExhausted
+	// TerminationNodeBudget means the max visited nodes limit was hit.
+	TerminationNodeBudget
+	// TerminationDistanceLimit means the target could not be reached without leaving the max distance from start.
+	TerminationDistanceLimit
+	// TerminationCancelled means the context of the search was cancelled.
+	TerminationCancelled
+)
+
+// Err returns the sentinel error matching the reason, or nil if the target was reached.
+func (r TerminationReason) Err() error {
+	switch r {
+	case TerminationReached:
+		return nil
+	case TerminationExhausted:
+		return ErrUnreachable
+	case TerminationNodeBudget:
+		return ErrNodeBudget
+	case TerminationDistanceLimit:
+		return ErrDistanceLimit
+	case TerminationCancelled:
+		return ErrCancelled
+	default:
+		panic("should not happen")
+	}
+}
+
+// String ...
+func (r TerminationReason) String() string {
+	switch r {
+	case TerminationReached:
+		return "reached"
+	case TerminationExhausted:
+		return "exhausted"
+	case TerminationNodeBudget:
+		return "node budget"
+	case TerminationDistanceLimit:
+		return "distance limit"
+	case TerminationCancelled:
+		return "cancelled"
+	default:
+		return "unknown"
+	}
+}
+
+// Result is the outcome of a path search.
+type Result struct {
+	// Path is the path found, or the best partial path if the target was not reached.
+	Path *Path
+	// Target is the target the Path leads to.
+	Target *Target
+	// Reason is the reason the search stopped.
+	Reason TerminationReason
+	// VisitedNodes is the amount of nodes visited during the search.
+	VisitedNodes int
+	// Elapsed is the time the search took.
+	Elapsed time.Duration
+}
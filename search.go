@@ -0,0 +1,307 @@
+package pathfind
+
+import (
+	"github.com/df-mc/dragonfly/server/block/cube"
+	"github.com/df-mc/dragonfly/server/world"
+	"math"
+	"slices"
+	"time"
+)
+
+// deadlineCheckInterval is the amount of nodes visited between two deadline checks.
+const deadlineCheckInterval = 16
+
+// Budget limits the amount of work done by a single Search.Step call. Zero values mean no limit.
+type Budget struct {
+	// Nodes is the max amount of nodes visited in the step.
+	Nodes int
+	// Time is the max amount of time spent in the step.
+	Time time.Duration
+}
+
+// SourceUpdater may be implemented by a NodeEvaluator that can have its world.BlockSource replaced while
+// a search is running, for example when the search is resumed in a new world transaction.
+type SourceUpdater interface {
+	// UpdateSource replaces the world.BlockSource of the evaluator.
+	UpdateSource(source world.BlockSource)
+}
+
+// Search is an A* search that can be advanced in steps, so that it may be spread across several ticks.
+// The NodeEvaluator stays prepared from NewSearch until the search is finished or cancelled, so it must
+// not be used by another search in the meantime.
+type Search struct {
+	evaluator NodeEvaluator
+	source    world.BlockSource
+	// lineChecker is set if the search looks for any-angle paths.
+	lineChecker LineChecker
+	startNode   *Node
+	targets     []*Target
+	openSet     *BinaryHeap
+
+	heuristic Heuristic
+	weight    float64
+
+	maxVisitedNodes         int
+	maxDistanceFromStart    float64
+	maxDistanceFromStartSqr float64
+	reachRange              int
+
+	distanceLimited bool
+	finished        bool
+	result          Result
+
+	// path and pathNodes hold the Path of the result, so that their memory is reused by Reset.
+	path      Path
+	pathNodes []*Node
+	// reuse is true if the result may point to the memory of the evaluator, which is reused by its next
+	// search. Otherwise, the nodes of the path and the target are copied.
+	reuse bool
+}
+
+// NewSearch prepares the evaluator and returns a Search from pos to the nearest of targets. An optional
+// Heuristic may be passed like with FindPath.
+func NewSearch(evaluator NodeEvaluator, source world.BlockSource, pos cube.Pos, targets []cube.Pos, maxVisitedNodes int, maxDistanceFromStart float64, reachRange int, heuristic ...Heuristic) (*Search, error) {
+	s := &Search{}
+	if err := s.Reset(evaluator, source, pos, targets, maxVisitedNodes, maxDistanceFromStart, reachRange, heuristic...); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Reset starts a new search like NewSearch, reusing the memory of the previous search. The previous search
+// is cancelled if it was not finished, and its Path must no longer be used. The TieBreaker is kept.
+func (s *Search) Reset(evaluator NodeEvaluator, source world.BlockSource, pos cube.Pos, targets []cube.Pos, maxVisitedNodes int, maxDistanceFromStart float64, reachRange int, heuristic ...Heuristic) error {
+	if len(targets) == 0 {
+		return ErrNoTargets
+	}
+	if s.evaluator != nil && !s.finished {
+		s.evaluator.Done()
+	}
+	evaluator.Prepare(source, pos)
+	h, weight := heuristicOf(heuristic)
+
+	openSet := s.openSet
+	if openSet == nil {
+		openSet = NewBinaryHeap()
+	}
+	openSet.Clear()
+	*s = Search{
+		evaluator:               evaluator,
+		source:                  source,
+		startNode:               evaluator.StartNode(),
+		targets:                 s.targets[:0],
+		openSet:                 openSet,
+		heuristic:               h,
+		weight:                  weight,
+		maxVisitedNodes:         maxVisitedNodes,
+		maxDistanceFromStart:    maxDistanceFromStart,
+		maxDistanceFromStartSqr: maxDistanceFromStart * maxDistanceFromStart,
+		reachRange:              reachRange,
+		pathNodes:               s.pathNodes[:0],
+	}
+	for _, target := range targets {
+		s.targets = append(s.targets, evaluator.Goal(target))
+	}
+	if anyAngle, ok := evaluator.(AnyAngleEvaluator); ok && anyAngle.AnyAngle() {
+		s.lineChecker = anyAngle
+	}
+
+	s.startNode.g = 0
+	s.startNode.h = bestHeuristic(s.startNode, s.heuristic, s.targets...)
+	s.startNode.f = s.startNode.h
+	s.openSet.Insert(s.startNode)
+	return nil
+}
+
+// Step advances the search within the budget passed and reports if the search is finished.
+func (s *Search) Step(budget Budget) (done bool) {
+	if s.finished {
+		return true
+	}
+	start := time.Now()
+	var deadline time.Time
+	if budget.Time > 0 {
+		deadline = start.Add(budget.Time)
+	}
+	s.step(nil, budget.Nodes, deadline)
+	s.result.Elapsed += time.Since(start)
+	return s.finished
+}
+
+// SetSource replaces the world.BlockSource used by the evaluator if it implements SourceUpdater. It should be
+// called before each Step if the previous source is no longer valid.
+func (s *Search) SetSource(source world.BlockSource) {
+	s.source = source
+	if updater, ok := s.evaluator.(SourceUpdater); ok {
+		updater.UpdateSource(source)
+	}
+}
+
+// SetTieBreaker changes how nodes of the same cost are ordered for the rest of the search.
+func (s *Search) SetTieBreaker(tieBreaker TieBreaker) {
+	nodes := slices.Clone(s.openSet.GetHeap())
+	s.openSet.Clear()
+	s.openSet = NewBinaryHeap(tieBreaker)
+	for _, node := range nodes {
+		s.openSet.Insert(node)
+	}
+}
+
+// Cancel stops the search, releasing the evaluator. The best partial path found so far is kept.
+func (s *Search) Cancel() {
+	if !s.finished {
+		s.finish(TerminationCancelled)
+	}
+}
+
+// Done reports if the search is finished.
+func (s *Search) Done() bool {
+	return s.finished
+}
+
+// VisitedNodes returns the amount of nodes visited so far.
+func (s *Search) VisitedNodes() int {
+	return s.result.VisitedNodes
+}
+
+// Path returns the path found. It returns nil while the search is not finished.
+func (s *Search) Path() *Path {
+	return s.result.Path
+}
+
+// Result returns the outcome of the search. ErrInProgress is returned while the search is not finished.
+func (s *Search) Result() (Result, error) {
+	if !s.finished {
+		return s.result, ErrInProgress
+	}
+	return s.result, s.result.Reason.Err()
+}
+
+// step visits at most nodes nodes (unlimited if zero) until the search is finished, done is closed or the
+// deadline is passed.
+func (s *Search) step(done <-chan struct{}, nodes int, deadline time.Time) {
+	for n := 0; !s.finished && (nodes <= 0 || n < nodes); n++ {
+		if !deadline.IsZero() && n%deadlineCheckInterval == 0 && n > 0 && time.Now().After(deadline) {
+			return
+		}
+		select {
+		case <-done:
+			s.finish(TerminationCancelled)
+			return
+		default:
+		}
+		s.visitNext()
+	}
+}
+
+// visitNext pops the next node from the open set and expands it.
+func (s *Search) visitNext() {
+	if s.openSet.IsEmpty() {
+		s.finish(TerminationExhausted)
+		return
+	}
+	s.result.VisitedNodes++
+	if s.result.VisitedNodes >= s.maxVisitedNodes {
+		s.finish(TerminationNodeBudget)
+		return
+	}
+
+	current := s.openSet.Pop()
+	current.Closed = true
+
+	reached := false
+	for _, target := range s.targets {
+		if current.distanceManhattan(target.Pos) <= s.reachRange {
+			target.SetReached(true)
+			reached = true
+		}
+	}
+	if reached {
+		s.finish(TerminationReached)
+		return
+	}
+	if current.distanceSquared(s.startNode) >= s.maxDistanceFromStartSqr {
+		s.distanceLimited = true
+		return
+	}
+	for _, neighbor := range s.evaluator.Neighbors(current) {
+		parent := s.parentOf(current, neighbor)
+		distance := parent.distance(neighbor)
+		neighbor.walkedDistance = parent.walkedDistance + distance
+
+		if neighbor.walkedDistance >= s.maxDistanceFromStart {
+			s.distanceLimited = true
+			continue
+		}
+
+		newNeighborG := parent.g + distance + neighbor.CostMalus
+		if !neighbor.OpenSet() || newNeighborG < neighbor.g {
+			neighbor.cameFrom = parent
+			neighbor.g = newNeighborG
+			neighbor.h = bestHeuristic(neighbor, s.heuristic, s.targets...) * s.weight
+
+			if neighbor.OpenSet() {
+				s.openSet.ChangeCost(neighbor, neighbor.g+neighbor.h)
+			} else {
+				neighbor.f = neighbor.g + neighbor.h
+				s.openSet.Insert(neighbor)
+			}
+		}
+	}
+}
+
+// bestPath builds the shortest path to a reached target, or the path that ends closest to any target if none
+// of them were reached.
+func (s *Search) bestPath() (*Path, *Target) {
+	reached := slices.ContainsFunc(s.targets, (*Target).Reached)
+	var (
+		best         *Target
+		bestCount    int
+		bestDistance float64
+	)
+	for _, target := range s.targets {
+		if target.Reached() != reached {
+			continue
+		}
+		count, distance := pathLength(target.BestNode()), math.Inf(1)
+		if count > 0 {
+			distance = float64(target.BestNode().distanceManhattan(target.Pos))
+		}
+		switch {
+		case best == nil,
+			reached && count < bestCount,
+			!reached && distance < bestDistance,
+			!reached && distance == bestDistance && count < bestCount:
+			best, bestCount, bestDistance = target, count, distance
+		}
+	}
+	s.pathNodes = appendPath(s.pathNodes[:0], best.BestNode())
+	if !s.reuse {
+		target := *best
+		s.path = makePath(detachNodes(s.pathNodes), reached, best.Pos)
+		return &s.path, &target
+	}
+	s.path = makePath(s.pathNodes, reached, best.Pos)
+	return &s.path, best
+}
+
+// parentOf returns the node the neighbor is connected to when reached from the current node. This is the
+// node the current node was reached from if the search looks for any-angle paths and it is in a straight
+// line from the neighbor, or the current node otherwise.
+func (s *Search) parentOf(current, neighbor *Node) *Node {
+	if s.lineChecker != nil && current.cameFrom != nil && s.lineChecker.CanMoveDirectly(s.source, current.cameFrom, neighbor) {
+		return current.cameFrom
+	}
+	return current
+}
+
+// finish ends the search with the reason passed and builds the resulting path.
+func (s *Search) finish(reason TerminationReason) {
+	if reason == TerminationExhausted && s.distanceLimited {
+		reason = TerminationDistanceLimit
+	}
+	s.result.Reason = reason
+	s.result.Path, s.result.Target = s.bestPath()
+	s.finished = true
+	s.evaluator.Done()
+}
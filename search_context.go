@@ -0,0 +1,30 @@
+package pathfind
+
+import (
+	"github.com/df-mc/dragonfly/server/block/cube"
+	"github.com/df-mc/dragonfly/server/world"
+	"time"
+)
+
+// SearchContext runs searches reusing the memory of a single Search, so that path finding stops allocating
+// once the context has grown to the size of its searches. Evaluators should be reused as well, as they
+// hold the nodes of the searches. A SearchContext is not safe for concurrent use.
+type SearchContext struct {
+	search  Search
+	targets [1]cube.Pos
+}
+
+// FindPath builds a pathfind.Path like the FindPath function. The Path and the nodes in it are only valid
+// until the next search run with the context or the evaluator.
+func (c *SearchContext) FindPath(evaluator NodeEvaluator, source world.BlockSource, pos, target cube.Pos, maxVisitedNodes int, maxDistanceFromStart float64, reachRange int, heuristic ...Heuristic) *Path {
+	c.targets[0] = target
+	_ = c.search.Reset(evaluator, source, pos, c.targets[:], maxVisitedNodes, maxDistanceFromStart, reachRange, heuristic...)
+	c.search.reuse = true
+	c.search.step(nil, 0, time.Time{})
+	return c.search.result.Path
+}
+
+// Search returns the Search used by the context, which holds the Result of the last search.
+func (c *SearchContext) Search() *Search {
+	return &c.search
+}
@@ -0,0 +1,43 @@
+package pathfind_test
+
+import (
+	"testing"
+
+	pathfind "github.com/FDUTCH/Pathfinder"
+	"github.com/FDUTCH/Pathfinder/evaluator"
+	"github.com/df-mc/dragonfly/server/block/cube"
+)
+
+func TestSearchContextAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("the race detector allocates")
+	}
+	source := walledPlains(64)
+	e := evaluator.WalkNodeEvaluatorConfig{Box: cube.Box(-0.3, 0, -0.3, 0.3, 1.8, 0.3), Cache: evaluator.NewPathTypeCache()}.New()
+	var c pathfind.SearchContext
+
+	allocs := testing.AllocsPerRun(20, func() {
+		if p := c.FindPath(e, source, cube.Pos{1, 0, 1}, cube.Pos{60, 0, 25}, 20000, 256, 0); !p.Reached() {
+			t.Fatal("target not reached")
+		}
+	})
+	if allocs != 0 {
+		t.Fatalf("expected no allocations in steady state, got %v per search", allocs)
+	}
+}
+
+func BenchmarkSearchContext(b *testing.B) {
+	source := walledPlains(64)
+	e := evaluator.WalkNodeEvaluatorConfig{Box: cube.Box(-0.3, 0, -0.3, 0.3, 1.8, 0.3), Cache: evaluator.NewPathTypeCache()}.New()
+	var c pathfind.SearchContext
+	c.FindPath(e, source, cube.Pos{1, 0, 1}, cube.Pos{60, 0, 25}, 20000, 256, 0, pathfind.Euclidean)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.FindPath(e, source, cube.Pos{1, 0, 1}, cube.Pos{60, 0, 25}, 20000, 256, 0, pathfind.Euclidean)
+	}
+	b.ReportMetric(testing.AllocsPerRun(10, func() {
+		c.FindPath(e, source, cube.Pos{1, 0, 1}, cube.Pos{60, 0, 25}, 20000, 256, 0, pathfind.Euclidean)
+	}), "steady-allocs/op")
+}
@@ -0,0 +1,118 @@
+package pathfind_test
+
+import (
+	"context"
+	"math"
+	"slices"
+	"testing"
+
+	pathfind "github.com/FDUTCH/Pathfinder"
+	"github.com/FDUTCH/Pathfinder/evaluator"
+	"github.com/FDUTCH/Pathfinder/internal/testworld"
+	"github.com/df-mc/dragonfly/server/block/cube"
+)
+
+func benchmarkSearch(b *testing.B, tieBreaker pathfind.TieBreaker) {
+	source := walledPlains(64)
+	var visited int
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		e := evaluator.WalkNodeEvaluatorConfig{Box: cube.Box(-0.3, 0, -0.3, 0.3, 1.8, 0.3)}.New()
+		s, err := pathfind.NewSearch(e, source, cube.Pos{1, 0, 1}, []cube.Pos{{60, 0, 25}}, 20000, 256, 0, pathfind.Euclidean)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if tieBreaker != nil {
+			s.SetTieBreaker(tieBreaker)
+		}
+		s.Step(pathfind.Budget{})
+		result, err := s.Result()
+		if err != nil {
+			b.Fatal(err)
+		}
+		visited += result.VisitedNodes
+	}
+	b.ReportMetric(float64(visited)/float64(b.N), "nodes/op")
+}
+
+func BenchmarkSearch(b *testing.B) {
+	benchmarkSearch(b, nil)
+}
+
+func BenchmarkSearchPreferLowerHeuristic(b *testing.B) {
+	benchmarkSearch(b, pathfind.PreferLowerHeuristic)
+}
+
+// trench is a raised floor with a trench one block deep at x=3, as wide as the world.
+const trench = `
+###.###
+###.###
+###.###
+###.###
+###.###
+###.###
+###.###`
+
+func TestSearchAnyAngle(t *testing.T) {
+	tests := []struct {
+		name          string
+		layers        []string
+		config        evaluator.WalkNodeEvaluatorConfig
+		start, target cube.Pos
+		// via holds positions the path must pass, as they cannot be skipped in a straight line.
+		via []cube.Pos
+		// wantCount is the amount of nodes the path should have, without the start. It is not checked if zero.
+		wantCount int
+	}{
+		{
+			name:      "open ground",
+			layers:    []string{floor, open, open},
+			start:     cube.Pos{0, 1, 0},
+			target:    cube.Pos{6, 1, 3},
+			wantCount: 1,
+		},
+		{
+			name:   "drop",
+			layers: []string{floor, trench, open, open},
+			start:  cube.Pos{0, 2, 3},
+			target: cube.Pos{6, 2, 3},
+			via:    []cube.Pos{{3, 1, 3}},
+		},
+		{
+			name:   "door",
+			layers: []string{floor, doorway, doorway},
+			config: evaluator.WalkNodeEvaluatorConfig{CanPathDoors: true, CanOpenDoors: true},
+			start:  cube.Pos{0, 1, 3},
+			target: cube.Pos{6, 1, 3},
+			via:    []cube.Pos{{3, 1, 3}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.Box = cube.Box(-0.3, 0, -0.3, 0.3, 1.8, 0.3)
+			tt.config.AnyAngle = true
+			result, err := pathfind.FindPathContext(context.Background(), tt.config.New(), testworld.New(tt.layers...), tt.start, tt.target, 1000, 64, 0)
+			if err != nil {
+				t.Fatalf("FindPathContext() error = %v", err)
+			}
+			p := result.Path
+			if tt.wantCount != 0 && p.Count() != tt.wantCount {
+				t.Errorf("Count() = %v, want %v", p.Count(), tt.wantCount)
+			}
+			if tt.wantCount == 1 {
+				if got, want := pathLength(tt.start, p), tt.target.Vec3().Sub(tt.start.Vec3()).Len(); math.Abs(got-want) > 1e-9 {
+					t.Errorf("path length = %v, want %v", got, want)
+				}
+			}
+			var positions []cube.Pos
+			for i := 0; i < p.Count(); i++ {
+				positions = append(positions, p.Node(i).Pos)
+			}
+			for _, pos := range tt.via {
+				if !slices.Contains(positions, pos) {
+					t.Errorf("path %v does not pass %v", positions, pos)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package pathfind
+
+import (
+	"github.com/df-mc/dragonfly/server/block"
+	"github.com/df-mc/dragonfly/server/block/cube"
+	"github.com/df-mc/dragonfly/server/world"
+	"math"
+)
+
+// MaxSnapshotDistance is the max distance from start that SnapshotAround copies the region for.
+const MaxSnapshotDistance = 64
+
+// snapshotMargin is the amount of blocks copied beyond the max distance from start, so that collision and
+// fall checks near the edge of the search still read real blocks.
+const snapshotMargin = 4
+
+// Snapshot is an immutable copy of a cuboid region of a world.BlockSource. It is safe for concurrent use,
+// so it may be used for pathfinding outside the world goroutine. Blocks outside the region are reported as
+// block.Barrier, so that paths never leave the region.
+type Snapshot struct {
+	min, max cube.Pos
+	blocks   []world.Block
+}
+
+// NewSnapshot copies all blocks between min and max (inclusive) from source.
+func NewSnapshot(source world.BlockSource, min, max cube.Pos) *Snapshot {
+	for i := range 3 {
+		if min[i] > max[i] {
+			min[i], max[i] = max[i], min[i]
+		}
+	}
+	s := &Snapshot{min: min, max: max}
+	size := max.Sub(min).Add(cube.Pos{1, 1, 1})
+	s.blocks = make([]world.Block, 0, size.X()*size.Y()*size.Z())
+
+	for x := min.X(); x <= max.X(); x++ {
+		for z := min.Z(); z <= max.Z(); z++ {
+			for y := min.Y(); y <= max.Y(); y++ {
+				s.blocks = append(s.blocks, source.Block(cube.Pos{x, y, z}))
+			}
+		}
+	}
+	return s
+}
+
+// SnapshotAround copies the chunk aligned region that a search from pos limited by maxDistanceFromStart
+// may read. The distance is limited to MaxSnapshotDistance. If the source has a Range method, like
+// *world.Tx, the region is limited to its height range.
+//
+// All blocks are read on the calling goroutine. The region spans the distance plus a margin of 4 blocks in
+// every direction, rounded out to whole chunks, so at MaxSnapshotDistance it is 10 by 10 chunks wide and 137
+// blocks high, or about 3.5 million blocks. SnapshotBetween copies far less for targets close to pos.
+func SnapshotAround(source world.BlockSource, pos cube.Pos, maxDistanceFromStart float64) *Snapshot {
+	lower, upper := snapshotRegion(pos, maxDistanceFromStart)
+	lower[0], lower[2] = lower[0]&^15, lower[2]&^15
+	upper[0], upper[2] = upper[0]|15, upper[2]|15
+	lower, upper = clampToRange(source, lower, upper)
+	return NewSnapshot(source, lower, upper)
+}
+
+// SnapshotBetween copies the box spanning pos and the targets passed, grown by margin blocks in every
+// direction so that a search may detour around obstacles between them. The box is limited to the region
+// SnapshotAround copies for the same distance and, like there, to the height range of the source. Paths
+// never leave the box, so the margin should leave room for the detours needed.
+func SnapshotBetween(source world.BlockSource, pos cube.Pos, targets []cube.Pos, maxDistanceFromStart float64, margin int) *Snapshot {
+	lower, upper := snapshotRegion(pos, maxDistanceFromStart)
+	boxMin, boxMax := pos, pos
+	for _, target := range targets {
+		for i := range 3 {
+			boxMin[i], boxMax[i] = min(boxMin[i], target[i]), max(boxMax[i], target[i])
+		}
+	}
+	margin = max(margin, 0)
+	for i := range 3 {
+		lower[i], upper[i] = max(lower[i], boxMin[i]-margin), min(upper[i], boxMax[i]+margin)
+	}
+	lower, upper = clampToRange(source, lower, upper)
+	return NewSnapshot(source, lower, upper)
+}
+
+// snapshotRegion returns the corners of the region around pos that a search limited by maxDistanceFromStart
+// may read, including snapshotMargin. The distance is limited to MaxSnapshotDistance.
+func snapshotRegion(pos cube.Pos, maxDistanceFromStart float64) (lower, upper cube.Pos) {
+	if !(maxDistanceFromStart >= 0) {
+		maxDistanceFromStart = 0
+	}
+	radius := int(math.Ceil(min(maxDistanceFromStart, MaxSnapshotDistance))) + snapshotMargin
+	return pos.Sub(cube.Pos{radius, radius, radius}), pos.Add(cube.Pos{radius, radius, radius})
+}
+
+// clampToRange limits the y coordinates of the corners passed to the height range of the source if it has a
+// Range method.
+func clampToRange(source world.BlockSource, lower, upper cube.Pos) (cube.Pos, cube.Pos) {
+	if ranged, ok := source.(interface{ Range() cube.Range }); ok {
+		r := ranged.Range()
+		lower[1], upper[1] = min(max(lower[1], r.Min()), r.Max()), min(max(upper[1], r.Min()), r.Max())
+	}
+	return lower, upper
+}
+
+// Block ...
+func (s *Snapshot) Block(pos cube.Pos) world.Block {
+	if !s.Contains(pos) {
+		return block.Barrier{}
+	}
+	rel := pos.Sub(s.min)
+	height := s.max.Y() - s.min.Y() + 1
+	length := s.max.Z() - s.min.Z() + 1
+	return s.blocks[(rel.X()*length+rel.Z())*height+rel.Y()]
+}
+
+// Contains reports if the position passed is inside the region of the snapshot.
+func (s *Snapshot) Contains(pos cube.Pos) bool {
+	for i := range 3 {
+		if pos[i] < s.min[i] || pos[i] > s.max[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Bounds returns the min and max corners of the region of the snapshot.
+func (s *Snapshot) Bounds() (min, max cube.Pos) {
+	return s.min, s.max
+}
@@ -0,0 +1,90 @@
+package pathfind_test
+
+import (
+	"math"
+	"testing"
+
+	pathfind "github.com/FDUTCH/Pathfinder"
+	"github.com/FDUTCH/Pathfinder/internal/testworld"
+	"github.com/df-mc/dragonfly/server/block"
+	"github.com/df-mc/dragonfly/server/block/cube"
+)
+
+// rangedWorld is a testworld.World with the height range of the overworld, like a *world.Tx.
+type rangedWorld struct {
+	*testworld.World
+}
+
+func (rangedWorld) Range() cube.Range {
+	return cube.Range{-64, 319}
+}
+
+func TestNewSnapshot(t *testing.T) {
+	w := testworld.New("#.\n.~", "D.\n..")
+	s := pathfind.NewSnapshot(w, cube.Pos{1, 1, 1}, cube.Pos{0, 0, 0})
+	if min, max := s.Bounds(); min != (cube.Pos{}) || max != (cube.Pos{1, 1, 1}) {
+		t.Fatalf("Bounds() = %v, %v, want %v, %v", min, max, cube.Pos{}, cube.Pos{1, 1, 1})
+	}
+	for _, pos := range []cube.Pos{{0, 0, 0}, {1, 0, 1}, {0, 1, 0}, {1, 1, 1}} {
+		if got, want := s.Block(pos), w.Block(pos); got != want {
+			t.Errorf("Block(%v) = %v, want %v", pos, got, want)
+		}
+	}
+
+	// Blocks are copied, so changes to the source are not seen.
+	w.Set(cube.Pos{1, 1, 1}, block.Stone{})
+	if _, ok := s.Block(cube.Pos{1, 1, 1}).(block.Air); !ok {
+		t.Errorf("Block() after changing the source = %v, want air", s.Block(cube.Pos{1, 1, 1}))
+	}
+	if _, ok := s.Block(cube.Pos{2, 0, 0}).(block.Barrier); !ok {
+		t.Errorf("Block() outside the region = %v, want a barrier", s.Block(cube.Pos{2, 0, 0}))
+	}
+}
+
+func TestSnapshotAround(t *testing.T) {
+	tests := []struct {
+		name     string
+		pos      cube.Pos
+		distance float64
+		min, max cube.Pos
+	}{
+		{name: "chunk aligned", pos: cube.Pos{20, 64, -3}, distance: 8, min: cube.Pos{0, 52, -16}, max: cube.Pos{47, 76, 15}},
+		{name: "clamped to bottom", pos: cube.Pos{0, -60, 0}, distance: 8, min: cube.Pos{-16, -64, -16}, max: cube.Pos{15, -48, 15}},
+		{name: "outside range", pos: cube.Pos{0, 400, 0}, distance: 8, min: cube.Pos{-16, 319, -16}, max: cube.Pos{15, 319, 15}},
+		{name: "max distance", pos: cube.Pos{0, 64, 0}, distance: math.Inf(1), min: cube.Pos{-80, -4, -80}, max: cube.Pos{79, 132, 79}},
+		{name: "negative distance", pos: cube.Pos{0, 64, 0}, distance: -8, min: cube.Pos{-16, 60, -16}, max: cube.Pos{15, 68, 15}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := pathfind.SnapshotAround(rangedWorld{testworld.New()}, tt.pos, tt.distance)
+			if min, max := s.Bounds(); min != tt.min || max != tt.max {
+				t.Errorf("Bounds() = %v, %v, want %v, %v", min, max, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestSnapshotBetween(t *testing.T) {
+	tests := []struct {
+		name     string
+		pos      cube.Pos
+		targets  []cube.Pos
+		distance float64
+		margin   int
+		min, max cube.Pos
+	}{
+		{name: "no targets", pos: cube.Pos{20, 64, -3}, distance: 8, margin: 2, min: cube.Pos{18, 62, -5}, max: cube.Pos{22, 66, -1}},
+		{name: "targets", pos: cube.Pos{20, 64, -3}, targets: []cube.Pos{{24, 66, -6}, {17, 64, 0}}, distance: 8, margin: 2, min: cube.Pos{15, 62, -8}, max: cube.Pos{26, 68, 2}},
+		{name: "limited by distance", pos: cube.Pos{0, 64, 0}, targets: []cube.Pos{{100, 64, -100}}, distance: 8, margin: 2, min: cube.Pos{-2, 62, -12}, max: cube.Pos{12, 66, 2}},
+		{name: "clamped to bottom", pos: cube.Pos{0, -63, 0}, targets: []cube.Pos{{2, -63, 0}}, distance: 8, margin: 2, min: cube.Pos{-2, -64, -2}, max: cube.Pos{4, -61, 2}},
+		{name: "negative margin", pos: cube.Pos{0, 64, 0}, targets: []cube.Pos{{2, 64, 0}}, distance: 8, margin: -2, min: cube.Pos{0, 64, 0}, max: cube.Pos{2, 64, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := pathfind.SnapshotBetween(rangedWorld{testworld.New()}, tt.pos, tt.targets, tt.distance, tt.margin)
+			if min, max := s.Bounds(); min != tt.min || max != tt.max {
+				t.Errorf("Bounds() = %v, %v, want %v, %v", min, max, tt.min, tt.max)
+			}
+		})
+	}
+}
@@ -31,3 +31,8 @@ func (t *Target) SetReached(reached bool) {
 func (t *Target) Reached() bool {
 	return t.reached
 }
+
+// Reset prepares the target for reuse in another search, like NewTarget.
+func (t *Target) Reset(node *Node) {
+	*t = Target{Node: *node, bestHeuristic: math.Inf(1)}
+}